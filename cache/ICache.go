@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss returned by Get when key not exist or already expired
+var ErrCacheMiss = errors.New("cache miss")
+
+// ICache is a byte oriented key value store, so a redis compatible backend can be plugged in
+type ICache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiredAt time.Time
+}
+
+// LRUCache is in memory cache, the least recently used key removed when capacity is full
+type LRUCache struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	mutex    sync.Mutex
+}
+
+// function provider
+func NewLRUCache(capacity int) ICache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LRUCache{
+		capacity: capacity,
+		items:    map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (l *LRUCache) Get(ctx context.Context, key string) ([]byte, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expiredAt.IsZero() && time.Now().After(entry.expiredAt) {
+		l.remove(element)
+		return nil, ErrCacheMiss
+	}
+
+	l.order.MoveToFront(element)
+	return append([]byte(nil), entry.value...), nil
+}
+
+// Set save value, ttl <= 0 means never expired
+func (l *LRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var expiredAt time.Time
+	if ttl > 0 {
+		expiredAt = time.Now().Add(ttl)
+	}
+
+	value = append([]byte(nil), value...)
+	if element, ok := l.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expiredAt = value, expiredAt
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiredAt: expiredAt})
+	if l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+	return nil
+}
+
+func (l *LRUCache) Delete(ctx context.Context, keys ...string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, ok := l.items[key]; ok {
+			l.remove(element)
+		}
+	}
+	return nil
+}
+
+func (l *LRUCache) DeletePrefix(ctx context.Context, prefix string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+	return nil
+}
+
+func (l *LRUCache) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*lruEntry).key)
+}
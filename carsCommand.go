@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"cobaApp/config"
+	"cobaApp/database"
+	"cobaApp/helper"
+	"cobaApp/repository"
+	"cobaApp/service"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+)
+
+// runCars handle `cobaApp cars import|export ...` through the same service used by the http api.
+// the repository is not cached, the server cache expire by its ttl
+func runCars(cfg config.IConfig, log *logrus.Logger, args []string) int {
+	if len(args) < 2 || len(args) > 3 || (args[0] != "import" && args[0] != "export") {
+		return usageError("cars")
+	}
+
+	ctx := context.Background()
+	db, err := database.ConnectDatabase(ctx, cfg, log)
+	if err != nil {
+		log.Errorf("cant connect database : %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	carService := service.NewCarService(db, helper.NewValidator(), repository.NewCarRepository(db), cfg)
+
+	if args[0] == "import" {
+		return importCars(ctx, carService, log, args[1:])
+	}
+
+	return exportCars(ctx, carService, log, args[1:])
+}
+
+// importCars read args [file, mode], format is taken from file extension
+func importCars(ctx context.Context, carService service.ICarService, log *logrus.Logger, args []string) int {
+	format, ok := helper.CarFileFormat(args[0])
+	if !ok {
+		fmt.Fprintln(os.Stderr, "file extension must be .csv or .ndjson")
+		return 2
+	}
+
+	mode := ""
+	if len(args) > 1 {
+		mode = args[1]
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		log.Errorf("cant open file : %v", err)
+		return 1
+	}
+	defer file.Close()
+
+	result, err := carService.Import(ctx, file, format, mode)
+	if result != nil {
+		content, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(content))
+	}
+	if err != nil {
+		log.Errorf("cant import cars : %v", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// exportCars read args [format, file], without file the cars are written to stdout
+func exportCars(ctx context.Context, carService service.ICarService, log *logrus.Logger, args []string) int {
+	format, ok := helper.CarFileFormat(args[0])
+	if !ok {
+		fmt.Fprintln(os.Stderr, "format must be csv or ndjson")
+		return 2
+	}
+
+	var w io.Writer = os.Stdout
+	if len(args) > 1 {
+		file, err := os.Create(args[1])
+		if err != nil {
+			log.Errorf("cant create file : %v", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffer := bufio.NewWriter(w)
+	if err := carService.Export(ctx, buffer, format); err != nil {
+		log.Errorf("cant export cars : %v", err)
+		return 1
+	}
+	if err := buffer.Flush(); err != nil {
+		log.Errorf("cant export cars : %v", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cobaApp/config"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(cfg config.IConfig, log *logrus.Logger, args []string) int
+}
+
+// commands of the binary, without command the server is started
+var commands map[string]command
+
+// registered in init because command refer back to commands for its usage
+func init() {
+	commands = map[string]command{
+		"serve":   {"serve", runServe},
+		"migrate": {"migrate up|down|status|to <version>", runMigrate},
+		"config":  {"config print|validate", runConfig},
+		"cars":    {"cars import <file> [atomic|best_effort] | cars export <csv|ndjson> [file]", runCars},
+		"seed":    {"seed [fixture.yaml|fixture.json...] | seed generate <count> [random seed]", runSeed},
+	}
+}
+
+// runCommand dispatch args to the command and return the process exit code
+func runCommand(cfg config.IConfig, log *logrus.Logger, args []string) int {
+	if len(args) == 0 {
+		return runServe(cfg, log, nil)
+	}
+
+	if args[0] == "help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	// keep stdout for command output, e.g. cars export
+	if args[0] != "serve" {
+		log.SetOutput(os.Stderr)
+	}
+
+	return cmd.run(cfg, log, args[1:])
+}
+
+func printUsage(w *os.File) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage : cobaApp [flags] <command>")
+	fmt.Fprintln(w, "commands :")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %v\n", commands[name].usage)
+	}
+	fmt.Fprintln(w, "run cobaApp --help to list every flag")
+}
+
+// usageError print usage of the command and return exit code of wrong usage
+func usageError(name string) int {
+	fmt.Fprintf(os.Stderr, "usage : cobaApp %v\n", commands[name].usage)
+	return 2
+}
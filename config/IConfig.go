@@ -1,5 +1,11 @@
 package config
 
+import "context"
+
 type IConfig interface {
 	GetConfig() *ConfigApp
+	Args() []string
+	Subscribe(fn func(cfg *ConfigApp))
+	Reload() error
+	Watch(ctx context.Context, onReload func(err error)) error
 }
@@ -1,70 +1,119 @@
 package config
 
 import (
-	"github.com/spf13/viper"
-	"log"
+	"os"
+	"sync"
+	"time"
 )
 
 type ConfigApp struct {
-	App      *App
-	Database *Database
-	Jaeger   *Jaeger
+	App       *App       `json:"app"`
+	Database  *Database  `json:"database"`
+	Jaeger    *Jaeger    `json:"jaeger"`
+	Log       *Log       `json:"log"`
+	Trash     *Trash     `json:"trash"`
+	Http      *Http      `json:"http"`
+	Cache     *Cache     `json:"cache"`
+	RateLimit *RateLimit `json:"rate_limit"`
+	Health    *Health    `json:"health"`
 }
 
 type App struct {
-	Name   string `json:"name"`
-	Port   int    `json:"port"`
-	Author string `json:"author"`
+	Name            string        `json:"name"`
+	Port            int           `json:"port"`
+	Author          string        `json:"author"`
+	CursorSecret    Secret        `json:"cursor_secret"`
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	DrainDelay      time.Duration `json:"drain_delay"`
 }
 
 type Database struct {
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	Host     string `json:"host"`
-	Name     string `json:"name"`
+	Port              int           `json:"port"`
+	User              string        `json:"user"`
+	Password          Secret        `json:"password"`
+	Host              string        `json:"host"`
+	Name              string        `json:"name"`
+	MaxOpenConns      int           `json:"max_open_conns"`
+	MaxIdleConns      int           `json:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `json:"conn_max_idle_time"`
+	ConnectRetry      int           `json:"connect_retry"`
+	ConnectBackoff    time.Duration `json:"connect_backoff"`
+	ConnectMaxBackoff time.Duration `json:"connect_max_backoff"`
+	ConnectTimeout    time.Duration `json:"connect_timeout"`
 }
 
 type Jaeger struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host         string  `json:"host"`
+	Port         int     `json:"port"`
+	SamplerType  string  `json:"sampler_type"`
+	SamplerParam float64 `json:"sampler_param"`
+	LogSpans     bool    `json:"log_spans"`
 }
+
+type Log struct {
+	Level string `json:"level"`
+}
+
+type Trash struct {
+	Retention time.Duration `json:"retention"`
+}
+
+// error response format
+const (
+	ErrorFormatApiResponse = "api_response"
+	ErrorFormatProblem     = "problem"
+)
+
+type Http struct {
+	CacheControl string `json:"cache_control"`
+	ErrorFormat  string `json:"error_format"`
+}
+
+type Cache struct {
+	Enabled  bool          `json:"enabled"`
+	Capacity int           `json:"capacity"`
+	TTL      time.Duration `json:"ttl"`
+}
+
+type RateLimit struct {
+	Enabled bool          `json:"enabled"`
+	Max     int           `json:"max"`
+	Window  time.Duration `json:"window"`
+}
+
+type Health struct {
+	Timeout time.Duration `json:"timeout"`
+}
+
 type Config struct {
 	ConfigApp *ConfigApp
+
+	args        []string
+	commands    []string
+	path        string
+	mutex       sync.RWMutex
+	subscribers []func(cfg *ConfigApp)
 }
 
 // method function provider
-func NewConfig() IConfig {
-	cfg := viper.New()
-	cfg.SetConfigFile("config.json")
-	cfg.SetConfigType("json")
-	cfg.AddConfigPath("./")
-
-	if err := cfg.ReadInConfig(); err != nil {
-		log.Fatalf("cant load config : %v", err)
+func NewConfig() (IConfig, error) {
+	config, err := LoadConfig(os.Args[1:])
+	if err != nil {
+		return nil, err
 	}
 
-	config := &ConfigApp{
-		App: &App{
-			Name:   cfg.GetString("app.name"),
-			Port:   cfg.GetInt("app.port"),
-			Author: cfg.GetString("app.author"),
-		},
-		Database: &Database{
-			Port:     cfg.GetInt("database.port"),
-			User:     cfg.GetString("database.user"),
-			Password: cfg.GetString("database.password"),
-			Host:     cfg.GetString("database.host"),
-			Name:     cfg.GetString("database.name"),
-		},
-		Jaeger: &Jaeger{
-			Host: cfg.GetString("jaeger.host"),
-			Port: cfg.GetInt("jaeger.port"),
-		},
-	}
-	return &Config{config}
+	return config, nil
+}
+
+// Args return positional argument left after flags, e.g. [migrate up]
+func (c *Config) Args() []string {
+	return c.commands
 }
 
 func (c *Config) GetConfig() *ConfigApp {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.ConfigApp
 }
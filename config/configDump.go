@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Dump return configuration in the same shape as config.json, duration is written as "1m30s"
+// and secret is redacted, so the result is safe to print
+func (c *ConfigApp) Dump() map[string]map[string]any {
+	result := map[string]map[string]any{}
+
+	app := reflect.ValueOf(c).Elem()
+	for i := 0; i < app.NumField(); i++ {
+		section := app.Field(i)
+		if section.IsNil() {
+			continue
+		}
+
+		values := map[string]any{}
+		section = section.Elem()
+		for j := 0; j < section.NumField(); j++ {
+			key := jsonName(section.Type().Field(j))
+
+			switch value := section.Field(j).Interface().(type) {
+			case time.Duration:
+				values[key] = value.String()
+			case Secret:
+				values[key] = value.String()
+			default:
+				values[key] = value
+			}
+		}
+
+		result[jsonName(app.Type().Field(i))] = values
+	}
+
+	return result
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
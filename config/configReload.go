@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// editor and kubernetes configmap write the file in several events, wait until they settle
+const reloadDebounce = 200 * time.Millisecond
+
+// RestartRequiredError returned by Reload when a setting that only apply on start was changed.
+// the rest of the configuration is still applied, Keys keep their running value
+type RestartRequiredError struct {
+	Keys []string
+}
+
+func (r *RestartRequiredError) Error() string {
+	return "config reloaded without restart setting, restart needed to change : " + strings.Join(r.Keys, ", ")
+}
+
+// restartSettings are read once on start (listener, connection, reporter, cache size),
+// field return pointer to the setting so its running value can be kept
+var restartSettings = []struct {
+	key   string
+	field func(cfg *ConfigApp) any
+}{
+	{"app.port", func(cfg *ConfigApp) any { return &cfg.App.Port }},
+	{"database.host", func(cfg *ConfigApp) any { return &cfg.Database.Host }},
+	{"database.port", func(cfg *ConfigApp) any { return &cfg.Database.Port }},
+	{"database.user", func(cfg *ConfigApp) any { return &cfg.Database.User }},
+	{"database.password", func(cfg *ConfigApp) any { return &cfg.Database.Password }},
+	{"database.name", func(cfg *ConfigApp) any { return &cfg.Database.Name }},
+	{"jaeger.host", func(cfg *ConfigApp) any { return &cfg.Jaeger.Host }},
+	{"jaeger.port", func(cfg *ConfigApp) any { return &cfg.Jaeger.Port }},
+	{"jaeger.log_spans", func(cfg *ConfigApp) any { return &cfg.Jaeger.LogSpans }},
+	{"cache.enabled", func(cfg *ConfigApp) any { return &cfg.Cache.Enabled }},
+	{"cache.capacity", func(cfg *ConfigApp) any { return &cfg.Cache.Capacity }},
+}
+
+// Subscribe register fn to be called with the new configuration after every successful reload
+func (c *Config) Subscribe(fn func(cfg *ConfigApp)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.subscribers = append(c.subscribers, fn)
+}
+
+// Reload load configuration again with the same args, the current one is kept when it fail.
+// setting that need restart keep the running value and is reported by *RestartRequiredError
+func (c *Config) Reload() error {
+	next, err := LoadConfig(c.args)
+	if err != nil {
+		return err
+	}
+
+	current := c.GetConfig()
+
+	var keys []string
+	for _, setting := range restartSettings {
+		currentValue := reflect.ValueOf(setting.field(current)).Elem()
+		nextValue := reflect.ValueOf(setting.field(next.ConfigApp)).Elem()
+		if !nextValue.Equal(currentValue) {
+			keys = append(keys, setting.key)
+			nextValue.Set(currentValue)
+		}
+	}
+
+	c.mutex.Lock()
+	c.ConfigApp = next.ConfigApp
+	subscribers := append([]func(cfg *ConfigApp){}, c.subscribers...)
+	c.mutex.Unlock()
+
+	for _, fn := range subscribers {
+		fn(next.ConfigApp)
+	}
+
+	if len(keys) > 0 {
+		return &RestartRequiredError{Keys: keys}
+	}
+	return nil
+}
+
+// Watch reload configuration when the config file change or the process receive SIGHUP,
+// onReload receive the result of every reload until ctx is done
+func (c *Config) Watch(ctx context.Context, onReload func(err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// watch the directory, the file itself is replaced on atomic save
+	var file string
+	if c.path != "" {
+		file = filepath.Clean(c.path)
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hangup)
+
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				onReload(c.Reload())
+			case event := <-watcher.Events:
+				if filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					debounce.Reset(reloadDebounce)
+				}
+			case <-debounce.C:
+				onReload(c.Reload())
+			case err := <-watcher.Errors:
+				onReload(err)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+)
+
+const (
+	// EncryptedPrefix mark a value encrypted with the local secret key, e.g. "enc:base64..."
+	EncryptedPrefix = "enc:"
+
+	// FileSuffix on a key read the value from file, e.g. database.password_file
+	FileSuffix = "_file"
+
+	secretKeySetting = "secret.key"
+	redacted         = "******"
+)
+
+// Secret is a string that never show its value when printed, logged or marshalled
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", s.String())), nil
+}
+
+// resolveSecrets replace every string setting with the content of its _file and decrypt enc: value.
+// the secret key itself can come from secret.key_file, so it is resolved first
+func resolveSecrets(cfg *viper.Viper, report *ValidationError) {
+	resolveFile(cfg, report, secretKeySetting)
+
+	var key []byte
+	if value := cfg.GetString(secretKeySetting); value != "" {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(decoded) != 32 {
+			report.add(secretKeySetting, "must be base64 of 32 bytes")
+		} else {
+			key = decoded
+		}
+	}
+
+	for _, s := range settings {
+		if _, ok := s.value.(string); !ok || s.key == secretKeySetting {
+			continue
+		}
+
+		if !resolveFile(cfg, report, s.key) {
+			continue
+		}
+
+		value := cfg.GetString(s.key)
+		if !strings.HasPrefix(value, EncryptedPrefix) {
+			continue
+		}
+
+		if key == nil {
+			report.add(s.key, "is encrypted but %v is not set", secretKeySetting)
+			continue
+		}
+
+		plain, err := DecryptSecret(key, value)
+		if err != nil {
+			report.add(s.key, "cant decrypt : %v", err)
+			continue
+		}
+		cfg.Set(s.key, plain)
+	}
+}
+
+// resolveFile read key from key_file when it is set, the file win over plain value. return false when it fail
+func resolveFile(cfg *viper.Viper, report *ValidationError, key string) bool {
+	path := cfg.GetString(key + FileSuffix)
+	if path == "" {
+		return true
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		report.add(key+FileSuffix, "cant read secret file : %v", err)
+		return false
+	}
+
+	// secret mount usually end with new line
+	cfg.Set(key, strings.TrimRight(string(content), "\r\n"))
+	return true
+}
+
+// EncryptSecret encrypt plain text with AES-GCM, the result can be put in config as it is
+func EncryptSecret(key []byte, plain string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret is the reverse of EncryptSecret
+func DecryptSecret(key []byte, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted value too short")
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("wrong key or corrupted value")
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvPrefix prefix every environment variable, e.g. COBAAPP_DATABASE_HOST for database.host
+	EnvPrefix = "COBAAPP"
+
+	defaultConfigFile = "config.json"
+)
+
+type setting struct {
+	key   string
+	value any
+	usage string
+}
+
+// settings is the lowest layer, every key here can be set by file, env and flag
+var settings = []setting{
+	{"app.name", "cobaApp", "application name"},
+	{"app.port", 5005, "http port"},
+	{"app.author", "", "application author"},
+	{"app.cursor_secret", "", "secret to sign pagination cursor"},
+	{"app.shutdown_timeout", 10 * time.Second, "max time to drain in-flight request on shutdown"},
+	{"app.drain_delay", 5 * time.Second, "time /readyz fail before listener is closed on shutdown, longer than readiness probe period"},
+	{"database.host", "localhost", "mysql host"},
+	{"database.port", 3306, "mysql port"},
+	{"database.user", "root", "mysql user"},
+	{"database.password", "", "mysql password"},
+	{"database.name", "cobaApp", "mysql database name"},
+	{"database.max_open_conns", 50, "max open connection of the pool"},
+	{"database.max_idle_conns", 30, "max idle connection of the pool"},
+	{"database.conn_max_lifetime", 30 * time.Minute, "max lifetime of a connection"},
+	{"database.conn_max_idle_time", 20 * time.Minute, "max idle time of a connection"},
+	{"database.connect_retry", 10, "how many times ping is retried on start"},
+	{"database.connect_backoff", time.Second, "first wait between ping retry, doubled every retry"},
+	{"database.connect_max_backoff", 30 * time.Second, "max wait between ping retry"},
+	{"database.connect_timeout", 5 * time.Second, "timeout of each ping"},
+	{"jaeger.host", "localhost", "jaeger agent host"},
+	{"jaeger.port", 6831, "jaeger agent port"},
+	{"jaeger.sampler_type", "const", "jaeger sampler type (const, probabilistic, ratelimiting, remote)"},
+	{"jaeger.sampler_param", float64(1), "jaeger sampler param"},
+	{"jaeger.log_spans", false, "log every span reported"},
+	{"secret.key", "", "base64 AES-256 key to decrypt enc: values"},
+	{"log.level", "debug", "log level (trace, debug, info, warn, error)"},
+	{"trash.retention", 30 * 24 * time.Hour, "how long deleted car kept before purge"},
+	{"http.cache_control", "no-cache", "Cache-Control value for read responses"},
+	{"http.error_format", "api_response", "error response format (api_response, problem), problem+json is also sent when client accept it"},
+	{"cache.enabled", true, "enable repository cache"},
+	{"cache.capacity", 1000, "max entries of repository cache"},
+	{"cache.ttl", time.Minute, "repository cache ttl"},
+	{"rate_limit.enabled", false, "limit request per client ip"},
+	{"rate_limit.max", 100, "max request per client ip in one window"},
+	{"rate_limit.window", time.Minute, "rate limit window"},
+	{"health.timeout", 2 * time.Second, "timeout of each readiness check"},
+}
+
+// LoadConfig read configuration with precedence flag > env > config file > default.
+// any string setting can be read from file with _file suffix or encrypted with enc: prefix.
+// config file path come from --config flag, COBAAPP_CONFIG env or ./config.json.
+// invalid configuration return *ValidationError listing every problem
+func LoadConfig(args []string) (*Config, error) {
+	cfg := viper.New()
+
+	flags := pflag.NewFlagSet("cobaApp", pflag.ContinueOnError)
+	configFile := flags.String("config", "", fmt.Sprintf("config file path (env %v_CONFIG)", EnvPrefix))
+	for _, s := range settings {
+		cfg.SetDefault(s.key, s.value)
+
+		switch value := s.value.(type) {
+		case string:
+			flags.String(s.key, value, s.usage)
+		case int:
+			flags.Int(s.key, value, s.usage)
+		case float64:
+			flags.Float64(s.key, value, s.usage)
+		case bool:
+			flags.Bool(s.key, value, s.usage)
+		case time.Duration:
+			flags.Duration(s.key, value, s.usage)
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := cfg.BindPFlags(flags); err != nil {
+		return nil, err
+	}
+
+	cfg.SetEnvPrefix(EnvPrefix)
+	cfg.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	cfg.AutomaticEnv()
+
+	// config file is optional only when nobody ask for a specific one
+	path, required := *configFile, true
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "_CONFIG")
+	}
+	if path == "" {
+		path, required = defaultConfigFile, false
+	}
+
+	cfg.SetConfigFile(path)
+	cfg.SetConfigType("json")
+	if err := cfg.ReadInConfig(); err != nil {
+		if required || !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cant load config %v : %w", path, err)
+		}
+		path = ""
+	}
+
+	report := &ValidationError{}
+	resolveSecrets(cfg, report)
+	checkTypes(cfg, report)
+
+	configApp := newConfigApp(cfg)
+	configApp.validate(report)
+	if len(report.Problems) > 0 {
+		return nil, report
+	}
+
+	return &Config{ConfigApp: configApp, args: args, commands: flags.Args(), path: path}, nil
+}
+
+func newConfigApp(cfg *viper.Viper) *ConfigApp {
+	return &ConfigApp{
+		App: &App{
+			Name:            cfg.GetString("app.name"),
+			Port:            cfg.GetInt("app.port"),
+			Author:          cfg.GetString("app.author"),
+			CursorSecret:    Secret(cfg.GetString("app.cursor_secret")),
+			ShutdownTimeout: cfg.GetDuration("app.shutdown_timeout"),
+			DrainDelay:      cfg.GetDuration("app.drain_delay"),
+		},
+		Database: &Database{
+			Port:              cfg.GetInt("database.port"),
+			User:              cfg.GetString("database.user"),
+			Password:          Secret(cfg.GetString("database.password")),
+			Host:              cfg.GetString("database.host"),
+			Name:              cfg.GetString("database.name"),
+			MaxOpenConns:      cfg.GetInt("database.max_open_conns"),
+			MaxIdleConns:      cfg.GetInt("database.max_idle_conns"),
+			ConnMaxLifetime:   cfg.GetDuration("database.conn_max_lifetime"),
+			ConnMaxIdleTime:   cfg.GetDuration("database.conn_max_idle_time"),
+			ConnectRetry:      cfg.GetInt("database.connect_retry"),
+			ConnectBackoff:    cfg.GetDuration("database.connect_backoff"),
+			ConnectMaxBackoff: cfg.GetDuration("database.connect_max_backoff"),
+			ConnectTimeout:    cfg.GetDuration("database.connect_timeout"),
+		},
+		Jaeger: &Jaeger{
+			Host:         cfg.GetString("jaeger.host"),
+			Port:         cfg.GetInt("jaeger.port"),
+			SamplerType:  cfg.GetString("jaeger.sampler_type"),
+			SamplerParam: cfg.GetFloat64("jaeger.sampler_param"),
+			LogSpans:     cfg.GetBool("jaeger.log_spans"),
+		},
+		Log: &Log{
+			Level: cfg.GetString("log.level"),
+		},
+		Trash: &Trash{
+			Retention: cfg.GetDuration("trash.retention"),
+		},
+		Http: &Http{
+			CacheControl: cfg.GetString("http.cache_control"),
+			ErrorFormat:  cfg.GetString("http.error_format"),
+		},
+		Cache: &Cache{
+			Enabled:  cfg.GetBool("cache.enabled"),
+			Capacity: cfg.GetInt("cache.capacity"),
+			TTL:      cfg.GetDuration("cache.ttl"),
+		},
+		RateLimit: &RateLimit{
+			Enabled: cfg.GetBool("rate_limit.enabled"),
+			Max:     cfg.GetInt("rate_limit.max"),
+			Window:  cfg.GetDuration("rate_limit.window"),
+		},
+		Health: &Health{
+			Timeout: cfg.GetDuration("health.timeout"),
+		},
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+// ValidationError list every problem found in configuration, so all of them can be fixed at once
+type ValidationError struct {
+	Problems []string
+}
+
+func (v *ValidationError) Error() string {
+	return "invalid config :\n - " + strings.Join(v.Problems, "\n - ")
+}
+
+func (v *ValidationError) add(key string, format string, args ...any) {
+	v.Problems = append(v.Problems, fmt.Sprintf("%v : %v", key, fmt.Sprintf(format, args...)))
+}
+
+// checkTypes catch value that viper would silently turn into zero, e.g. port "abc" or ttl "1 minute"
+func checkTypes(cfg *viper.Viper, report *ValidationError) {
+	for _, s := range settings {
+		value := cfg.Get(s.key)
+
+		var err error
+		switch s.value.(type) {
+		case int:
+			_, err = cast.ToIntE(value)
+		case float64:
+			_, err = cast.ToFloat64E(value)
+		case bool:
+			_, err = cast.ToBoolE(value)
+		case time.Duration:
+			_, err = cast.ToDurationE(value)
+		}
+
+		if err != nil {
+			report.add(s.key, "invalid value %q", fmt.Sprint(value))
+		}
+	}
+}
+
+// Validate check the loaded configuration and return ValidationError with every problem
+func (c *ConfigApp) Validate() error {
+	report := &ValidationError{}
+	c.validate(report)
+
+	if len(report.Problems) > 0 {
+		return report
+	}
+	return nil
+}
+
+func (c *ConfigApp) validate(report *ValidationError) {
+	validate := validator.New()
+
+	// app
+	if c.App.Name == "" {
+		report.add("app.name", "is required")
+	}
+	checkPort(report, "app.port", c.App.Port)
+	if c.App.CursorSecret == "" {
+		report.add("app.cursor_secret", "is required")
+	}
+	checkDuration(report, "app.shutdown_timeout", c.App.ShutdownTimeout, true)
+	checkDuration(report, "app.drain_delay", c.App.DrainDelay, false)
+	if c.App.DrainDelay >= c.App.ShutdownTimeout {
+		report.add("app.drain_delay", "must be less than shutdown_timeout")
+	}
+
+	// database
+	checkHost(report, validate, "database.host", c.Database.Host)
+	checkPort(report, "database.port", c.Database.Port)
+	if c.Database.User == "" {
+		report.add("database.user", "is required")
+	}
+	if c.Database.Name == "" {
+		report.add("database.name", "is required")
+	}
+	if c.Database.MaxOpenConns < 0 {
+		report.add("database.max_open_conns", "cant be negative")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		report.add("database.max_idle_conns", "cant be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		report.add("database.max_idle_conns", "cant be greater than max_open_conns")
+	}
+	checkDuration(report, "database.conn_max_lifetime", c.Database.ConnMaxLifetime, false)
+	checkDuration(report, "database.conn_max_idle_time", c.Database.ConnMaxIdleTime, false)
+	if c.Database.ConnectRetry < 0 {
+		report.add("database.connect_retry", "cant be negative")
+	}
+	checkDuration(report, "database.connect_backoff", c.Database.ConnectBackoff, true)
+	checkDuration(report, "database.connect_max_backoff", c.Database.ConnectMaxBackoff, true)
+	if c.Database.ConnectMaxBackoff < c.Database.ConnectBackoff {
+		report.add("database.connect_max_backoff", "cant be less than connect_backoff")
+	}
+	checkDuration(report, "database.connect_timeout", c.Database.ConnectTimeout, true)
+
+	// jaeger
+	checkHost(report, validate, "jaeger.host", c.Jaeger.Host)
+	checkPort(report, "jaeger.port", c.Jaeger.Port)
+	switch c.Jaeger.SamplerType {
+	case "const":
+		if c.Jaeger.SamplerParam != 0 && c.Jaeger.SamplerParam != 1 {
+			report.add("jaeger.sampler_param", "must be 0 or 1 for const sampler")
+		}
+	case "probabilistic":
+		if c.Jaeger.SamplerParam < 0 || c.Jaeger.SamplerParam > 1 {
+			report.add("jaeger.sampler_param", "must be between 0 and 1 for probabilistic sampler")
+		}
+	case "ratelimiting", "remote":
+		if c.Jaeger.SamplerParam < 0 {
+			report.add("jaeger.sampler_param", "cant be negative")
+		}
+	default:
+		report.add("jaeger.sampler_type", "must be one of const, probabilistic, ratelimiting, remote")
+	}
+
+	// log
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		report.add("log.level", "unknown level %q", c.Log.Level)
+	}
+
+	// trash
+	checkDuration(report, "trash.retention", c.Trash.Retention, true)
+
+	// http
+	if c.Http.ErrorFormat != ErrorFormatApiResponse && c.Http.ErrorFormat != ErrorFormatProblem {
+		report.add("http.error_format", "must be one of %v, %v", ErrorFormatApiResponse, ErrorFormatProblem)
+	}
+
+	// cache
+	if c.Cache.Enabled {
+		if c.Cache.Capacity < 1 {
+			report.add("cache.capacity", "must be at least 1")
+		}
+		checkDuration(report, "cache.ttl", c.Cache.TTL, true)
+	}
+
+	// rate limit
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Max < 1 {
+			report.add("rate_limit.max", "must be at least 1")
+		}
+		checkDuration(report, "rate_limit.window", c.RateLimit.Window, true)
+	}
+
+	// health
+	checkDuration(report, "health.timeout", c.Health.Timeout, true)
+}
+
+func checkPort(report *ValidationError, key string, port int) {
+	if port < 1 || port > 65535 {
+		report.add(key, "must be between 1 and 65535, got %v", port)
+	}
+}
+
+func checkHost(report *ValidationError, validate *validator.Validate, key string, host string) {
+	if host == "" {
+		report.add(key, "is required")
+		return
+	}
+
+	if err := validate.Var(host, "hostname_rfc1123|ip"); err != nil {
+		report.add(key, "invalid hostname %q", host)
+	}
+}
+
+func checkDuration(report *ValidationError, key string, value time.Duration, positive bool) {
+	if positive && value <= 0 {
+		report.add(key, "must be greater than 0")
+	} else if value < 0 {
+		report.add(key, "cant be negative")
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"cobaApp/config"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+// runConfig handle `cobaApp config ...`. config is already loaded and validated before any command run,
+// an invalid config never reach here and the report is printed by main
+func runConfig(cfg config.IConfig, log *logrus.Logger, args []string) int {
+	if len(args) != 1 {
+		return usageError("config")
+	}
+
+	switch args[0] {
+	case "print":
+		content, err := json.MarshalIndent(cfg.GetConfig().Dump(), "", "  ")
+		if err != nil {
+			log.Errorf("cant print config : %v", err)
+			return 1
+		}
+		fmt.Println(string(content))
+	case "validate":
+		fmt.Println("config is valid")
+	default:
+		return usageError("config")
+	}
+
+	return 0
+}
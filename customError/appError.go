@@ -0,0 +1,107 @@
+package customError
+
+import "errors"
+
+// Detail is one field level problem, e.g. an invalid field of the request body
+type Detail struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// appError is embedded by every error kind, kind is the default code of the kind
+type appError struct {
+	kind    string
+	code    string
+	message string
+	cause   error
+	details []Detail
+}
+
+type Option func(a *appError)
+
+// WithCode replace the default code of the kind with a more specific one, e.g. car_not_found
+func WithCode(code string) Option {
+	return func(a *appError) {
+		a.code = code
+	}
+}
+
+// WithCause keep the original error, it is not shown to client but can be reached with errors.Unwrap
+func WithCause(err error) Option {
+	return func(a *appError) {
+		a.cause = err
+	}
+}
+
+// WithDetails add field level problems
+func WithDetails(details ...Detail) Option {
+	return func(a *appError) {
+		a.details = append(a.details, details...)
+	}
+}
+
+func newAppError(kind string, message string, options []Option) appError {
+	a := appError{kind: kind, code: kind, message: message}
+	for _, option := range options {
+		option(&a)
+	}
+
+	if a.message == "" && a.cause != nil {
+		a.message = a.cause.Error()
+	}
+	return a
+}
+
+func (a *appError) Error() string {
+	return a.message
+}
+
+func (a *appError) Unwrap() error {
+	return a.cause
+}
+
+// Code is a stable machine readable code, client can rely on it instead of the message
+func (a *appError) Code() string {
+	return a.code
+}
+
+func (a *appError) Details() []Detail {
+	return a.details
+}
+
+// Is match error of the same kind, a sentinel like ErrNotFound match every code of the kind
+func (a *appError) Is(target error) bool {
+	other, ok := target.(interface{ kindCode() (string, string) })
+	if !ok {
+		return false
+	}
+
+	kind, code := other.kindCode()
+	return kind == a.kind && (code == kind || code == a.code)
+}
+
+func (a *appError) kindCode() (string, string) {
+	return a.kind, a.code
+}
+
+// Code return code of the first custom error in the chain, empty when there is none
+func Code(err error) string {
+	var coder interface{ Code() string }
+	if errors.As(err, &coder) {
+		return coder.Code()
+	}
+
+	return ""
+}
+
+// Details return field level problems of the first custom error in the chain
+func Details(err error) []Detail {
+	var detailer interface{ Details() []Detail }
+	if errors.As(err, &detailer) {
+		return detailer.Details()
+	}
+
+	return nil
+}
@@ -1,14 +1,13 @@
 package customError
 
 type BadRequestError struct {
-	s string
+	appError
 }
 
-// function create new bad request error
-func NewBadRequestError(s string) error {
-	return &BadRequestError{s}
-}
+// ErrBadRequest match every bad request error with errors.Is
+var ErrBadRequest error = &BadRequestError{newAppError("bad_request", "bad request", nil)}
 
-func (b *BadRequestError) Error() string {
-	return b.s
+// function create new bad request error
+func NewBadRequestError(s string, options ...Option) error {
+	return &BadRequestError{newAppError("bad_request", s, options)}
 }
@@ -0,0 +1,13 @@
+package customError
+
+type ConflictError struct {
+	appError
+}
+
+// ErrConflict match every conflict error with errors.Is
+var ErrConflict error = &ConflictError{newAppError("conflict", "conflict", nil)}
+
+// function create new conflict error
+func NewConflictError(s string, options ...Option) error {
+	return &ConflictError{newAppError("conflict", s, options)}
+}
@@ -0,0 +1,61 @@
+package customError
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+type registryEntry struct {
+	match      func(err error) bool
+	statusCode int
+}
+
+var (
+	registry      []registryEntry
+	registryMutex sync.RWMutex
+)
+
+func init() {
+	Register[*BadRequestError](http.StatusBadRequest)
+	Register[*NotFoundError](http.StatusNotFound)
+	Register[*PreconditionFailedError](http.StatusPreconditionFailed)
+	Register[*PreconditionRequiredError](http.StatusPreconditionRequired)
+	Register[*ConflictError](http.StatusConflict)
+	Register[*UnauthorizedError](http.StatusUnauthorized)
+	Register[*ForbiddenError](http.StatusForbidden)
+	Register[*TooManyRequestsError](http.StatusTooManyRequests)
+	Register[*ServiceUnavailableError](http.StatusServiceUnavailable)
+	Register[*InternalServerError](http.StatusInternalServerError)
+}
+
+// Register map error of type T to statusCode, wrapped error is matched too.
+// the outermost registered error in the chain decide, so a cause never change the status of its wrapper
+func Register[T error](statusCode int) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registry = append(registry, registryEntry{
+		match: func(err error) bool {
+			_, ok := err.(T)
+			return ok
+		},
+		statusCode: statusCode,
+	})
+}
+
+// StatusCode return http status code registered for err, ok is false when no type match
+func StatusCode(err error) (statusCode int, ok bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		for _, entry := range registry {
+			if entry.match(err) {
+				return entry.statusCode, true
+			}
+		}
+	}
+
+	return http.StatusInternalServerError, false
+}
@@ -0,0 +1,13 @@
+package customError
+
+type ForbiddenError struct {
+	appError
+}
+
+// ErrForbidden match every forbidden error with errors.Is
+var ErrForbidden error = &ForbiddenError{newAppError("forbidden", "forbidden", nil)}
+
+// function create new forbidden error
+func NewForbiddenError(s string, options ...Option) error {
+	return &ForbiddenError{newAppError("forbidden", s, options)}
+}
@@ -1,14 +1,13 @@
 package customError
 
 type InternalServerError struct {
-	s string
+	appError
 }
 
-// function create new internal server error
-func NewInternalServerError(s string) error {
-	return &InternalServerError{s}
-}
+// ErrInternalServer match every internal server error with errors.Is
+var ErrInternalServer error = &InternalServerError{newAppError("internal_server_error", "internal server error", nil)}
 
-func (i *InternalServerError) Error() string {
-	return i.s
+// function create new internal server error
+func NewInternalServerError(s string, options ...Option) error {
+	return &InternalServerError{newAppError("internal_server_error", s, options)}
 }
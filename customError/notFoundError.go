@@ -1,14 +1,13 @@
 package customError
 
 type NotFoundError struct {
-	s string
+	appError
 }
 
-// function new not found error
-func NewNotFoundError(s string) error {
-	return &NotFoundError{s: s}
-}
+// ErrNotFound match every not found error with errors.Is
+var ErrNotFound error = &NotFoundError{newAppError("not_found", "not found", nil)}
 
-func (n *NotFoundError) Error() string {
-	return n.s
+// function new not found error
+func NewNotFoundError(s string, options ...Option) error {
+	return &NotFoundError{newAppError("not_found", s, options)}
 }
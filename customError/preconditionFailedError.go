@@ -0,0 +1,13 @@
+package customError
+
+type PreconditionFailedError struct {
+	appError
+}
+
+// ErrPreconditionFailed match every precondition failed error with errors.Is
+var ErrPreconditionFailed error = &PreconditionFailedError{newAppError("precondition_failed", "precondition failed", nil)}
+
+// function create new precondition failed error
+func NewPreconditionFailedError(s string, options ...Option) error {
+	return &PreconditionFailedError{newAppError("precondition_failed", s, options)}
+}
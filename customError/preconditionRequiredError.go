@@ -0,0 +1,13 @@
+package customError
+
+type PreconditionRequiredError struct {
+	appError
+}
+
+// ErrPreconditionRequired match every precondition required error with errors.Is
+var ErrPreconditionRequired error = &PreconditionRequiredError{newAppError("precondition_required", "precondition required", nil)}
+
+// function create new precondition required error
+func NewPreconditionRequiredError(s string, options ...Option) error {
+	return &PreconditionRequiredError{newAppError("precondition_required", s, options)}
+}
@@ -0,0 +1,13 @@
+package customError
+
+type ServiceUnavailableError struct {
+	appError
+}
+
+// ErrServiceUnavailable match every service unavailable error with errors.Is
+var ErrServiceUnavailable error = &ServiceUnavailableError{newAppError("service_unavailable", "service unavailable", nil)}
+
+// function create new service unavailable error
+func NewServiceUnavailableError(s string, options ...Option) error {
+	return &ServiceUnavailableError{newAppError("service_unavailable", s, options)}
+}
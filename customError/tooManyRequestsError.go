@@ -0,0 +1,13 @@
+package customError
+
+type TooManyRequestsError struct {
+	appError
+}
+
+// ErrTooManyRequests match every too many requests error with errors.Is
+var ErrTooManyRequests error = &TooManyRequestsError{newAppError("too_many_requests", "too many requests", nil)}
+
+// function create new too many requests error
+func NewTooManyRequestsError(s string, options ...Option) error {
+	return &TooManyRequestsError{newAppError("too_many_requests", s, options)}
+}
@@ -0,0 +1,13 @@
+package customError
+
+type UnauthorizedError struct {
+	appError
+}
+
+// ErrUnauthorized match every unauthorized error with errors.Is
+var ErrUnauthorized error = &UnauthorizedError{newAppError("unauthorized", "unauthorized", nil)}
+
+// function create new unauthorized error
+func NewUnauthorizedError(s string, options ...Option) error {
+	return &UnauthorizedError{newAppError("unauthorized", s, options)}
+}
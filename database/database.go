@@ -2,6 +2,7 @@ package database
 
 import (
 	"cobaApp/config"
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
@@ -9,23 +10,69 @@ import (
 	"time"
 )
 
-func ConnectDatabase(cfg config.IConfig, log *logrus.Logger) *sql.DB {
-	config := cfg.GetConfig().Database
+func ConnectDatabase(ctx context.Context, cfg config.IConfig, log *logrus.Logger) (*sql.DB, error) {
+	dbConfig := cfg.GetConfig().Database
 
 	dsn := fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local",
-		config.User, config.Password, config.Host, config.Port, config.Name)
+		dbConfig.User, dbConfig.Password.Value(), dbConfig.Host, dbConfig.Port, dbConfig.Name)
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
-		log.Fatalf("cant connect database : %v", err)
+		return nil, err
 	}
 
-	db.SetMaxOpenConns(50)
-	db.SetMaxIdleConns(30)
-	db.SetConnMaxLifetime(30 * time.Minute)
-	db.SetConnMaxIdleTime(20 * time.Minute)
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+
+	// sql.Open never connect, make sure the database really answer before serving
+	if err := WaitDatabase(ctx, db, dbConfig, log); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// pool size can change without reconnect
+	cfg.Subscribe(func(configApp *config.ConfigApp) {
+		db.SetMaxOpenConns(configApp.Database.MaxOpenConns)
+		db.SetMaxIdleConns(configApp.Database.MaxIdleConns)
+		db.SetConnMaxLifetime(configApp.Database.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(configApp.Database.ConnMaxIdleTime)
+	})
 
 	log.Infof("success connect database")
 
-	return db
+	return db, nil
+}
+
+// WaitDatabase ping until it success or the retry run out, the wait between attempt grow exponentially
+func WaitDatabase(ctx context.Context, db *sql.DB, dbConfig *config.Database, log *logrus.Logger) error {
+	backoff := dbConfig.ConnectBackoff
+	attempts := dbConfig.ConnectRetry + 1
+
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, dbConfig.ConnectTimeout)
+		err := db.PingContext(pingCtx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("cant ping database after %v attempt : %w", attempts, err)
+		}
+
+		log.Warnf("cant ping database (attempt %v/%v) : %v, retry in %v", attempt, attempts, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > dbConfig.ConnectMaxBackoff {
+			backoff = dbConfig.ConnectMaxBackoff
+		}
+	}
 }
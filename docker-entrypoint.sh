@@ -0,0 +1,8 @@
+#!/bin/sh
+set -e
+
+# schema must be up to date before the server accept request
+./cobaApp migrate up
+
+# replace the shell, so SIGTERM from docker reach the app and it shutdown gracefully
+exec ./cobaApp serve "$@"
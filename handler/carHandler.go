@@ -1,29 +1,33 @@
 package handler
 
 import (
+	"bufio"
 	"cobaApp/customError"
 	"cobaApp/helper"
 	"cobaApp/model/dto"
 	"cobaApp/service"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/sirupsen/logrus"
 	"net/http"
-	"strings"
+	"slices"
+	"time"
 )
 
 type CarHandler struct {
 	CarService service.ICarService
+	Translator *helper.ValidationTranslator
 	LogConsole *logrus.Logger
 }
 
 // function provider
-func NewCarHandler(carService service.ICarService, log *logrus.Logger) *CarHandler {
-	return &CarHandler{CarService: carService, LogConsole: log}
+func NewCarHandler(carService service.ICarService, translator *helper.ValidationTranslator, log *logrus.Logger) *CarHandler {
+	return &CarHandler{CarService: carService, Translator: translator, LogConsole: log}
 }
 
 // handler insert data
@@ -33,13 +37,7 @@ func (c *CarHandler) InsertData(ctx *fiber.Ctx) error {
 	// parsing body request
 	var request dto.InsertCarRequest
 	if err := ctx.BodyParser(&request); err != nil {
-		statusCode := http.StatusBadRequest
-		ctx.Status(statusCode)
-		return ctx.JSON(&dto.ApiResponse{
-			StatusCode: statusCode,
-			Status:     "bad request",
-			Message:    err.Error(),
-		})
+		return fail(span, customError.NewBadRequestError(err.Error()))
 	}
 
 	// log request to tracing
@@ -48,44 +46,12 @@ func (c *CarHandler) InsertData(ctx *fiber.Ctx) error {
 
 	// call service
 	newCar, err := c.CarService.Insert(ctxTracing, &request)
-
-	var statusCode int
-	if err != nil {
-		// cek if error validator
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			var errMessage []string
-			for _, errorField := range validationErrors {
-				errMessage = append(errMessage, fmt.Sprintf("error on field [%v] with tag [%v]",
-					errorField.Field(), errorField.ActualTag()))
-			}
-
-			statusCode = http.StatusBadRequest
-			return ctx.JSON(&dto.ApiResponse{
-				StatusCode: statusCode,
-				Status:     helper.CodeToStatus(statusCode),
-				Message:    strings.Join(errMessage, ". "),
-			})
-		}
-
-		switch err.(type) {
-		case *customError.BadRequestError:
-			statusCode = http.StatusBadRequest
-		case *customError.NotFoundError:
-			statusCode = http.StatusNotFound
-		default:
-			statusCode = http.StatusInternalServerError
-		}
-
-		ctx.Status(statusCode)
-		return ctx.JSON(&dto.ApiResponse{
-			StatusCode: statusCode,
-			Status:     helper.CodeToStatus(statusCode),
-			Message:    err.Error(),
-		})
+	if err != nil {
+		return fail(span, err)
 	}
 
 	// success insert
-	statusCode = http.StatusOK
+	statusCode := http.StatusOK
 	ctx.Status(statusCode)
 	return ctx.JSON(&dto.ApiResponse{
 		StatusCode: statusCode,
@@ -101,46 +67,57 @@ func (c *CarHandler) GetAll(ctx *fiber.Ctx) error {
 	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler GetAll")
 	defer span.Finish()
 
-	// call service
-	cars, err := c.CarService.GetAll(ctxTracing)
-	if err != nil {
-		var statusCode int
-		switch err.(type) {
-		case *customError.NotFoundError:
-			statusCode = http.StatusNotFound
-		case *customError.BadRequestError:
-			statusCode = http.StatusBadRequest
-		default:
-			statusCode = http.StatusInternalServerError
-		}
+	// parsing query spec
+	var query dto.CarQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return fail(span, customError.NewBadRequestError(err.Error()))
+	}
 
-		ctx.Status(statusCode)
+	// call service
+	cars, meta, err := c.CarService.GetAll(ctxTracing, &query)
+	if err != nil {
+		return fail(span, err)
+	}
 
-		response := dto.ApiResponse{
-			StatusCode: statusCode,
-			Status:     helper.CodeToStatus(statusCode),
-			Message:    err.Error(),
-		}
-		resJson, _ := json.Marshal(&response)
-		span.LogFields(log.String("response", string(resJson)))
-		return ctx.JSON(&response)
+	lastModified, err := c.CarService.LastModified(ctxTracing)
+	if err != nil {
+		return fail(span, err)
 	}
 
 	// success get data
 	statusCode := http.StatusOK
-	ctx.Status(statusCode)
 
 	response := dto.ApiResponse{
 		StatusCode: statusCode,
 		Status:     helper.CodeToStatus(statusCode),
 		Message:    "success get all data cars",
 		Data:       cars,
+		Meta:       meta,
 	}
 	resJson, _ := json.Marshal(&response)
+
+	// list has no single version, so etag come from the payload
+	if c.notModified(ctx, helper.PayloadETag(resJson), lastModified) {
+		span.LogFields(log.Int("status_code", http.StatusNotModified))
+		ctx.Status(http.StatusNotModified)
+		return nil
+	}
+
 	span.LogFields(log.String("response", string(resJson)))
+	ctx.Status(statusCode)
 	return ctx.JSON(&response)
 }
 
+// set etag and last-modified, then check the client copy is still fresh
+func (c *CarHandler) notModified(ctx *fiber.Ctx, etag string, lastModified time.Time) bool {
+	ctx.Set(fiber.HeaderETag, etag)
+	if !lastModified.IsZero() {
+		ctx.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	return helper.IsNotModified(ctx.Get(fiber.HeaderIfNoneMatch), ctx.Get(fiber.HeaderIfModifiedSince), etag, lastModified)
+}
+
 // handler get detail
 func (c *CarHandler) GetDetail(ctx *fiber.Ctx) error {
 	// start span
@@ -149,55 +126,421 @@ func (c *CarHandler) GetDetail(ctx *fiber.Ctx) error {
 
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		ctx.Status(statusCode)
-		response := dto.ApiResponse{
-			StatusCode: statusCode,
-			Status:     helper.CodeToStatus(statusCode),
-			Message:    "cant convert id to int",
-		}
-		resJson, _ := json.Marshal(&response)
-		span.LogFields(log.String("response", string(resJson)))
-
-		return ctx.JSON(response)
+		return fail(span, customError.NewBadRequestError("cant convert id to int"))
 	}
 
 	// call procedure in service
-	var statusCode int
 	car, err := c.CarService.GetDetail(ctxTracing, id)
 	if err != nil {
-		switch err.(type) {
-		case *customError.NotFoundError:
-			statusCode = http.StatusNotFound
-		case *customError.BadRequestError:
-			statusCode = http.StatusBadRequest
+		return fail(span, err)
+	}
+
+	// success get detail
+	updatedAt, _ := time.Parse(time.RFC3339, car.UpdatedAt)
+	if c.notModified(ctx, helper.VersionETag(car.Version), updatedAt) {
+		span.LogFields(log.Int("status_code", http.StatusNotModified))
+		ctx.Status(http.StatusNotModified)
+		return nil
+	}
+
+	resJson, _ := json.Marshal(&car)
+	span.LogFields(log.String("response", string(resJson)))
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    "success get data detail car",
+		Data:       car,
+	})
+}
+
+// handler update data
+func (c *CarHandler) UpdateData(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler UpdateData")
+	defer span.Finish()
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fail(span, customError.NewBadRequestError("cant convert id to int"))
+	}
+
+	// parsing body request
+	var request dto.UpdateCarRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return fail(span, customError.NewBadRequestError(err.Error()))
+	}
+
+	// log request to tracing
+	reqJson, _ := json.Marshal(&request)
+	span.LogFields(log.Int("id", id), log.String("request", string(reqJson)))
+
+	version, err := c.ifMatchVersion(ctx, ctxTracing, id)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// call service
+	car, err := c.CarService.Update(ctxTracing, id, version, &request)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// success update
+	ctx.Set(fiber.HeaderETag, helper.VersionETag(car.Version))
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    "success update data",
+		Data:       car,
+	})
+}
+
+// handler update sebagian data
+func (c *CarHandler) PatchData(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler PatchData")
+	defer span.Finish()
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fail(span, customError.NewBadRequestError("cant convert id to int"))
+	}
+
+	// parsing body request
+	var request dto.PatchCarRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return fail(span, customError.NewBadRequestError(err.Error()))
+	}
+
+	// log request to tracing
+	reqJson, _ := json.Marshal(&request)
+	span.LogFields(log.Int("id", id), log.String("request", string(reqJson)))
+
+	version, err := c.ifMatchVersion(ctx, ctxTracing, id)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// call service
+	car, err := c.CarService.Patch(ctxTracing, id, version, &request)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// success patch
+	ctx.Set(fiber.HeaderETag, helper.VersionETag(car.Version))
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    "success update data",
+		Data:       car,
+	})
+}
+
+// handler delete data
+func (c *CarHandler) DeleteData(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler DeleteData")
+	defer span.Finish()
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fail(span, customError.NewBadRequestError("cant convert id to int"))
+	}
+
+	span.LogFields(log.Int("id", id))
+
+	version, err := c.ifMatchVersion(ctx, ctxTracing, id)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// call service
+	if err := c.CarService.Delete(ctxTracing, id, version); err != nil {
+		return fail(span, err)
+	}
+
+	// success delete
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    "success delete data",
+	})
+}
+
+// get expected version from If-Match header, required for every change on car.
+// a single etag is used as is, * and etag list are resolved against the current version,
+// the write still check that version so nothing change in between
+func (c *CarHandler) ifMatchVersion(ctx *fiber.Ctx, ctxTracing context.Context, id int) (int, error) {
+	ifMatch := ctx.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return 0, customError.NewPreconditionRequiredError("header If-Match is required")
+	}
+
+	versions, wildcard, err := helper.ParseIfMatch(ifMatch)
+	if errors.Is(err, helper.ErrWeakETag) {
+		return 0, customError.NewPreconditionFailedError(err.Error())
+	}
+	if err != nil {
+		return 0, customError.NewBadRequestError("invalid If-Match : " + err.Error())
+	}
+	if !wildcard && len(versions) == 1 {
+		return versions[0], nil
+	}
+
+	car, err := c.CarService.GetDetail(ctxTracing, id)
+	if err != nil {
+		return 0, err
+	}
+	if wildcard || slices.Contains(versions, car.Version) {
+		return car.Version, nil
+	}
+
+	return 0, customError.NewPreconditionFailedError(fmt.Sprintf("car version is %v, not in If-Match", car.Version))
+}
+
+// handler insert banyak data sekaligus
+func (c *CarHandler) InsertBulk(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler InsertBulk")
+	defer span.Finish()
+
+	// parsing body request
+	var request []dto.InsertCarRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return fail(span, customError.NewBadRequestError(err.Error()))
+	}
+
+	// call service
+	result, err := c.CarService.InsertBulk(ctxTracing, request, ctx.Query("mode"))
+	c.translateResults(ctx, result)
+	if err != nil {
+		// atomic mode failed, keep the per row report
+		if result != nil {
+			err = &errorWithData{error: err, data: result}
+		}
+		return fail(span, err)
+	}
+
+	// success insert
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+
+	response := dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    fmt.Sprintf("success insert %v of %v data", result.Success, result.Total),
+		Data:       result,
+	}
+	resJson, _ := json.Marshal(&response)
+	span.LogFields(log.String("response", string(resJson)))
+	return ctx.JSON(&response)
+}
+
+// handler export semua data cars sebagai csv atau ndjson
+func (c *CarHandler) Export(ctx *fiber.Ctx) error {
+	// start span
+	span, _ := opentracing.StartSpanFromContext(ctx.Context(), "Handler Export")
+	defer span.Finish()
+
+	// format from query, then accept header, default csv
+	format, ok := helper.CarFileFormat(ctx.Query("format"))
+	if !ok && ctx.Query("format") != "" {
+		return fail(span, customError.NewBadRequestError("format must be csv or ndjson"))
+	}
+	if !ok {
+		switch ctx.Accepts("text/csv", "application/x-ndjson", "application/ndjson") {
+		case "application/x-ndjson", "application/ndjson":
+			format = helper.CarFileNDJSON
 		default:
-			statusCode = http.StatusInternalServerError
+			format = helper.CarFileCSV
 		}
+	}
+
+	span.LogFields(log.String("format", format))
 
-		ctx.Status(statusCode)
+	ctx.Set(fiber.HeaderContentType, helper.CarFileContentType(format))
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="cars.%v"`, format))
 
-		response := dto.ApiResponse{
-			StatusCode: statusCode,
-			Status:     helper.CodeToStatus(statusCode),
-			Message:    err.Error(),
+	// rows are written while the response is sent
+	spanContext := span.Context()
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamSpan := opentracing.StartSpan("Handler Export Stream", opentracing.ChildOf(spanContext))
+		defer streamSpan.Finish()
+
+		ctxTracing := opentracing.ContextWithSpan(context.Background(), streamSpan)
+		if err := c.CarService.Export(ctxTracing, w, format); err != nil {
+			streamSpan.LogFields(log.String("error", err.Error()))
+			c.LogConsole.Errorf("export cars stopped : %v", err)
 		}
+	})
 
-		resJson, _ := json.Marshal(&response)
-		span.LogFields(log.String("response", string(resJson)))
+	return nil
+}
+
+// handler import data cars dari file csv atau ndjson
+func (c *CarHandler) Import(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler Import")
+	defer span.Finish()
 
-		return ctx.JSON(&response)
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return fail(span, customError.NewBadRequestError("file is required"))
 	}
 
-	// success get detail
-	resJson, _ := json.Marshal(&car)
+	// format from query, then content type of file, then file extension
+	format, ok := helper.CarFileFormat(ctx.Query("format"))
+	if !ok {
+		format, ok = helper.CarFileFormat(file.Header.Get(fiber.HeaderContentType))
+	}
+	if !ok {
+		format, ok = helper.CarFileFormat(file.Filename)
+	}
+	if !ok {
+		return fail(span, customError.NewBadRequestError("format must be csv or ndjson"))
+	}
+
+	span.LogFields(log.String("file", file.Filename), log.String("format", format))
+
+	content, err := file.Open()
+	if err != nil {
+		return fail(span, customError.NewInternalServerError(err.Error(), customError.WithCause(err)))
+	}
+	defer content.Close()
+
+	// call service
+	result, err := c.CarService.Import(ctxTracing, content, format, ctx.Query("mode"))
+	c.translateResults(ctx, result)
+	if err != nil {
+		// import canceled, keep the per line report
+		if result != nil {
+			err = &errorWithData{error: err, data: result}
+		}
+		return fail(span, err)
+	}
+
+	// success import
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+
+	response := dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    fmt.Sprintf("success import %v of %v data", result.Success, result.Total),
+		Data:       result,
+	}
+	resJson, _ := json.Marshal(&response)
 	span.LogFields(log.String("response", string(resJson)))
-	statusCode = http.StatusOK
+	return ctx.JSON(&response)
+}
+
+// handler get all data cars yang ada di trash
+func (c *CarHandler) GetAllDeleted(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler GetAllDeleted")
+	defer span.Finish()
+
+	// parsing query spec
+	var query dto.CarQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return fail(span, customError.NewBadRequestError(err.Error()))
+	}
+
+	// call service
+	cars, meta, err := c.CarService.GetAllDeleted(ctxTracing, &query)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// success get data
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+
+	response := dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    "success get all deleted data cars",
+		Data:       cars,
+		Meta:       meta,
+	}
+	resJson, _ := json.Marshal(&response)
+	span.LogFields(log.String("response", string(resJson)))
+	return ctx.JSON(&response)
+}
+
+// handler restore data dari trash
+func (c *CarHandler) Restore(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler Restore")
+	defer span.Finish()
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fail(span, customError.NewBadRequestError("cant convert id to int"))
+	}
+
+	span.LogFields(log.Int("id", id))
+
+	// call service
+	car, err := c.CarService.Restore(ctxTracing, id)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// success restore
+	statusCode := http.StatusOK
 	ctx.Status(statusCode)
 	return ctx.JSON(&dto.ApiResponse{
 		StatusCode: statusCode,
 		Status:     helper.CodeToStatus(statusCode),
-		Message:    "success get data detail car",
+		Message:    "success restore data",
 		Data:       car,
 	})
 }
+
+// handler hapus permanen data di trash yang melewati masa retensi
+func (c *CarHandler) Purge(ctx *fiber.Ctx) error {
+	// start span
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx.Context(), "Handler Purge")
+	defer span.Finish()
+
+	// call service
+	total, err := c.CarService.Purge(ctxTracing)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// success purge
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    fmt.Sprintf("success purge %v data", total),
+		Data:       map[string]int{"purged": total},
+	})
+}
+
+// translateResults translate invalid row of bulk insert or import to the language from Accept-Language
+func (c *CarHandler) translateResults(ctx *fiber.Ctx, response *dto.BulkInsertCarResponse) {
+	if response == nil {
+		return
+	}
+
+	trans := c.Translator.Translator(ctx.Get(fiber.HeaderAcceptLanguage))
+	for i := range response.Results {
+		if err := response.Results[i].ValidationError; err != nil {
+			response.Results[i].Error = helper.TranslateValidationError(err, trans)
+			response.Results[i].Details = helper.ValidationErrorDetails(err, trans)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"cobaApp/config"
+	"cobaApp/customError"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+const (
+	// MIMEProblemJSON is content type of RFC 7807 problem document
+	MIMEProblemJSON = "application/problem+json"
+
+	// problem type is a urn, so it does not point to a page that must be hosted
+	problemTypePrefix = "urn:cobaApp:problem:"
+)
+
+func init() {
+	customError.Register[validator.ValidationErrors](http.StatusBadRequest)
+}
+
+// errorWithData keep partial result of a failed request, e.g. per row report of bulk insert
+type errorWithData struct {
+	error
+	data any
+}
+
+func (e *errorWithData) Unwrap() error {
+	return e.error
+}
+
+// NewErrorHandler write every error returned by handler as api response, status code come from
+// customError registry, then fiber error (e.g. route not found), otherwise internal server error.
+// error is written as problem+json when http.error_format is problem or the client accept it.
+// validation error is translated to the language from Accept-Language
+func NewErrorHandler(cfg config.IConfig, translator *helper.ValidationTranslator, logConsole *logrus.Logger) fiber.ErrorHandler {
+	return func(ctx *fiber.Ctx, err error) error {
+		statusCode, ok := customError.StatusCode(err)
+
+		var fiberError *fiber.Error
+		if !ok && errors.As(err, &fiberError) {
+			statusCode = fiberError.Code
+		}
+
+		if statusCode >= http.StatusInternalServerError {
+			if cause := errors.Unwrap(err); cause != nil {
+				logConsole.Errorf("%v %v failed : %v, cause : %v", ctx.Method(), ctx.Path(), err, cause)
+			} else {
+				logConsole.Errorf("%v %v failed : %v", ctx.Method(), ctx.Path(), err)
+			}
+		}
+
+		// error without code, e.g. from fiber or validator, get the code of its status
+		code := customError.Code(err)
+		if code == "" {
+			code = strings.ReplaceAll(helper.CodeToStatus(statusCode), " ", "_")
+		}
+
+		trans := translator.Translator(ctx.Get(fiber.HeaderAcceptLanguage))
+		message := helper.TranslateValidationError(err, trans)
+
+		details := customError.Details(err)
+		if details == nil {
+			details = helper.ValidationErrorDetails(err, trans)
+		}
+
+		var data any
+		var withData *errorWithData
+		if errors.As(err, &withData) {
+			data = withData.data
+		}
+
+		ctx.Status(statusCode)
+		if wantProblem(ctx, cfg) {
+			return ctx.JSON(&dto.ProblemResponse{
+				Type:     problemTypePrefix + code,
+				Title:    http.StatusText(statusCode),
+				Status:   statusCode,
+				Detail:   message,
+				Instance: ctx.OriginalURL(),
+				Code:     code,
+				Errors:   details,
+				Data:     data,
+			}, MIMEProblemJSON)
+		}
+
+		return ctx.JSON(&dto.ApiResponse{
+			StatusCode: statusCode,
+			Status:     helper.CodeToStatus(statusCode),
+			Code:       code,
+			Message:    message,
+			Details:    details,
+			Data:       data,
+		})
+	}
+}
+
+// wantProblem is true when config choose problem format or client prefer problem+json over json
+func wantProblem(ctx *fiber.Ctx, cfg config.IConfig) bool {
+	if httpConfig := cfg.GetConfig().Http; httpConfig != nil && httpConfig.ErrorFormat == config.ErrorFormatProblem {
+		return true
+	}
+
+	if ctx.Get(fiber.HeaderAccept) == "" {
+		return false
+	}
+	return ctx.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON
+}
+
+// fail record err in the handler span and return it to the error handler
+func fail(span opentracing.Span, err error) error {
+	span.LogFields(log.String("error", err.Error()))
+	return err
+}
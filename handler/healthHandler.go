@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"cobaApp/config"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"context"
+	"database/sql"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	healthUp   = "up"
+	healthDown = "down"
+)
+
+// HealthChecker is a dependency that can tell if it still work
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// HealthHandler serve probe endpoint, probe is called every few second so it is not traced
+type HealthHandler struct {
+	DB           *sql.DB
+	Tracer       HealthChecker
+	Config       config.IConfig
+	shuttingDown atomic.Bool
+}
+
+// function provider
+func NewHealthHandler(db *sql.DB, tracer HealthChecker, cfg config.IConfig) *HealthHandler {
+	return &HealthHandler{DB: db, Tracer: tracer, Config: cfg}
+}
+
+// MarkShuttingDown make readiness fail so no new traffic is routed while draining
+func (h *HealthHandler) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// handler liveness, process is alive when it can answer
+func (h *HealthHandler) Liveness(ctx *fiber.Ctx) error {
+	statusCode := http.StatusOK
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    "alive",
+		Data:       &dto.HealthResponse{Status: healthUp},
+	})
+}
+
+// handler readiness, check every dependency. tracer is not critical, losing span must not stop traffic
+func (h *HealthHandler) Readiness(ctx *fiber.Ctx) error {
+	response := dto.HealthResponse{Status: healthUp, Checks: map[string]dto.HealthCheck{}}
+
+	timeout := time.Duration(0)
+	if healthConfig := h.Config.GetConfig().Health; healthConfig != nil {
+		timeout = healthConfig.Timeout
+	}
+
+	response.Checks["database"] = h.check(ctx.Context(), timeout, true, func(checkCtx context.Context) error {
+		return h.DB.PingContext(checkCtx)
+	})
+	if h.Tracer != nil {
+		response.Checks["tracer"] = h.check(ctx.Context(), timeout, false, h.Tracer.Check)
+	}
+	if h.shuttingDown.Load() {
+		response.Checks["server"] = dto.HealthCheck{Status: healthDown, Critical: true, Error: "shutting down"}
+	}
+
+	for _, check := range response.Checks {
+		if check.Critical && check.Status != healthUp {
+			response.Status = healthDown
+		}
+	}
+
+	statusCode, message := http.StatusOK, "ready"
+	if response.Status != healthUp {
+		statusCode, message = http.StatusServiceUnavailable, "not ready"
+	}
+
+	ctx.Status(statusCode)
+	return ctx.JSON(&dto.ApiResponse{
+		StatusCode: statusCode,
+		Status:     helper.CodeToStatus(statusCode),
+		Message:    message,
+		Data:       &response,
+	})
+}
+
+func (h *HealthHandler) check(ctx context.Context, timeout time.Duration, critical bool, fn func(ctx context.Context) error) dto.HealthCheck {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+
+	result := dto.HealthCheck{Status: healthUp, Critical: critical, Latency: time.Since(start).String()}
+	if err != nil {
+		result.Status, result.Error = healthDown, err.Error()
+	}
+	return result
+}
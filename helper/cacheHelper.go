@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PayloadETag create weak etag from response body, used when data has no single version
+func PayloadETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`W/"%x"`, sum[:16])
+}
+
+// IsNotModified check conditional get header, If-None-Match win over If-Modified-Since (RFC 7232)
+func IsNotModified(ifNoneMatch string, ifModifiedSince string, etag string, lastModified time.Time) bool {
+	if ifNoneMatch != "" {
+		if etag == "" {
+			return false
+		}
+
+		for _, value := range strings.Split(ifNoneMatch, ",") {
+			value = strings.TrimSpace(value)
+			if value == "*" || weakETag(value) == weakETag(etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+
+		// http date only has second precision
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+func weakETag(value string) string {
+	return strings.TrimPrefix(value, "W/")
+}
@@ -0,0 +1,250 @@
+package helper
+
+import (
+	"bufio"
+	"cobaApp/model/dto"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// supported file format for import and export cars
+const (
+	CarFileCSV    = "csv"
+	CarFileNDJSON = "ndjson"
+)
+
+var carFileHeader = []string{"id", "name", "price", "release_date"}
+
+// CarFileRow is one parsed row from import file, Err is filled when the row cant be parsed
+type CarFileRow struct {
+	Line    int
+	Request dto.InsertCarRequest
+	Err     error
+}
+
+// CarFileFormat resolve format from format name, mime type or file name
+func CarFileFormat(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		value = mediaType
+	}
+
+	switch value {
+	case CarFileCSV, "text/csv", ".csv":
+		return CarFileCSV, true
+	case CarFileNDJSON, "jsonl", "application/x-ndjson", "application/ndjson", "application/jsonl", ".ndjson", ".jsonl":
+		return CarFileNDJSON, true
+	}
+
+	if ext := filepath.Ext(value); ext != "" && ext != value {
+		return CarFileFormat(ext)
+	}
+
+	return "", false
+}
+
+// CarFileContentType return content type of the format
+func CarFileContentType(format string) string {
+	if format == CarFileNDJSON {
+		return "application/x-ndjson"
+	}
+
+	return "text/csv"
+}
+
+// CarFileWriter write cars one by one to w, so the whole table never held in memory
+type CarFileWriter interface {
+	Write(car *dto.InsertCarResponse) error
+	Flush() error
+}
+
+func NewCarFileWriter(w io.Writer, format string) CarFileWriter {
+	if format == CarFileNDJSON {
+		buffer := bufio.NewWriter(w)
+		return &ndjsonCarWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+	}
+
+	return &csvCarWriter{writer: csv.NewWriter(w)}
+}
+
+type csvCarWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvCarWriter) Write(car *dto.InsertCarResponse) error {
+	if !c.headerWritten {
+		if err := c.writer.Write(carFileHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	return c.writer.Write([]string{
+		strconv.Itoa(car.Id),
+		car.Name,
+		strconv.FormatFloat(car.Price, 'f', -1, 64),
+		car.ReleaseDate,
+	})
+}
+
+func (c *csvCarWriter) Flush() error {
+	// empty table still get the header
+	if !c.headerWritten {
+		if err := c.writer.Write(carFileHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type ndjsonCarWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// ndjsonCarRecord keep ndjson line same column with csv file
+type ndjsonCarRecord struct {
+	Id          int     `json:"id"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	ReleaseDate string  `json:"release_date"`
+}
+
+func (n *ndjsonCarWriter) Write(car *dto.InsertCarResponse) error {
+	return n.encoder.Encode(&ndjsonCarRecord{
+		Id:          car.Id,
+		Name:        car.Name,
+		Price:       car.Price,
+		ReleaseDate: car.ReleaseDate,
+	})
+}
+
+func (n *ndjsonCarWriter) Flush() error {
+	return n.buffer.Flush()
+}
+
+// CarFileReader read import file row by row, so the whole file never held in memory
+type CarFileReader interface {
+	// Read return the next row, io.EOF after the last row
+	Read() (*CarFileRow, error)
+}
+
+// NewCarFileReader create reader of the format, csv header is read here
+func NewCarFileReader(r io.Reader, format string) (CarFileReader, error) {
+	if format == CarFileNDJSON {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonCarReader{scanner: scanner}, nil
+	}
+
+	return newCsvCarReader(r)
+}
+
+type csvCarReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCsvCarReader(r io.Reader) (*csvCarReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, fmt.Errorf("line 1: %w", err)
+	}
+
+	// map column name to position, so column order is free
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "price", "release_date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("line 1: missing column %v", required)
+		}
+	}
+
+	return &csvCarReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvCarReader) Read() (*CarFileRow, error) {
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+
+	if err != nil {
+		// malformed line is reported on its row, reading continue from the next line
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &CarFileRow{Line: parseErr.Line, Err: err}, nil
+		}
+		return nil, err
+	}
+
+	row := &CarFileRow{}
+	if len(record) > 0 {
+		row.Line, _ = c.reader.FieldPos(0)
+	}
+	get := func(column string) string {
+		if i := c.columns[column]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row.Request.Name = get("name")
+	row.Request.ReleaseDate = get("release_date")
+	if price := get("price"); price != "" {
+		row.Request.Price, err = strconv.ParseFloat(price, 64)
+		if err != nil {
+			row.Err = fmt.Errorf("invalid price %q", price)
+		}
+	}
+
+	return row, nil
+}
+
+type ndjsonCarReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonCarReader) Read() (*CarFileRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+		text := strings.TrimSpace(n.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := &CarFileRow{Line: n.line}
+		if err := StrictJSONUnmarshal([]byte(text), &row.Request); err != nil {
+			row.Err = err
+		}
+		return row, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %v: %w", n.line+1, err)
+	}
+
+	return nil, io.EOF
+}
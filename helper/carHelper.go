@@ -0,0 +1,8 @@
+package helper
+
+import "strings"
+
+// NormalizeName trim the name and collapse every whitespace between words into one space
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor serialize v to an opaque token signed with HMAC-SHA256
+func EncodeCursor(secret []byte, v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// DecodeCursor verify the signature of token and deserialize it into v
+func DecodeCursor(secret []byte, token string, v any) error {
+	payloadPart, signPart, found := strings.Cut(token, ".")
+	if !found {
+		return ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	sign, err := base64.RawURLEncoding.DecodeString(signPart)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(sign, mac.Sum(nil)) {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
@@ -1,12 +1,29 @@
 package helper
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
+// DateLayout is the only accepted format of release_date
+const DateLayout = "2006-01-02"
+
+// StringToDate convert validated date, invalid s become zero time so use ParseDate for user input
 func StringToDate(s string) time.Time {
-	date, _ := time.Parse("2006-01-02", s)
+	date, _ := time.Parse(DateLayout, s)
 	return date
 }
 
+// ParseDate convert s with format YYYY-MM-DD, e.g. 2020-13-45 or tomorrow is rejected
+func ParseDate(s string) (time.Time, error) {
+	date, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, must be YYYY-MM-DD", s)
+	}
+
+	return date, nil
+}
+
 func DateToString(d time.Time) string {
-	return d.Format("2006-01-02")
+	return d.Format(DateLayout)
 }
@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrWeakETag is returned for W/ etag in If-Match, it only allow strong comparison (RFC 7232)
+var ErrWeakETag = errors.New("weak etag cant be used in If-Match")
+
+// VersionETag create etag from version of the data
+func VersionETag(version int) string {
+	return fmt.Sprintf(`"%v"`, version)
+}
+
+// ParseIfMatch get versions from If-Match header value, a list of quoted version or *.
+// wildcard is true for *, which match whatever the current version is
+func ParseIfMatch(value string) (versions []int, wildcard bool, err error) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return nil, true, nil
+	}
+
+	for _, etag := range strings.Split(value, ",") {
+		etag = strings.TrimSpace(etag)
+		if etag == "" {
+			continue
+		}
+
+		version, err := parseVersionETag(etag)
+		if err != nil {
+			return nil, false, err
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, false, errors.New("etag must be a quoted version")
+	}
+
+	return versions, false, nil
+}
+
+func parseVersionETag(value string) (int, error) {
+	if strings.HasPrefix(value, "W/") {
+		return 0, ErrWeakETag
+	}
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return 0, errors.New("etag must be a quoted version")
+	}
+
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version < 1 {
+		return 0, errors.New("etag must be a quoted version")
+	}
+
+	return version, nil
+}
@@ -1,18 +1,41 @@
 package helper
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 func CodeToStatus(code int) string {
 	switch code {
 	case http.StatusOK:
 		return "ok"
+	case http.StatusNotModified:
+		return "not modified"
 	case http.StatusBadRequest:
 		return "bad request"
 	case http.StatusUnauthorized:
 		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
 	case http.StatusNotFound:
 		return "not found"
-	default:
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusPreconditionFailed:
+		return "precondition failed"
+	case http.StatusPreconditionRequired:
+		return "precondition required"
+	case http.StatusTooManyRequests:
+		return "too many requests"
+	case http.StatusServiceUnavailable:
+		return "service unavailable"
+	case http.StatusInternalServerError:
 		return "internal server error"
 	}
+
+	// other status, e.g. 405 from fiber, use its standard text
+	if text := http.StatusText(code); text != "" {
+		return strings.ToLower(text)
+	}
+	return "internal server error"
 }
@@ -0,0 +1,25 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// StrictJSONUnmarshal is json.Unmarshal that reject unknown field and data after the json value,
+// it is the fiber JSONDecoder so every body parser use it
+func StrictJSONUnmarshal(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("json: unexpected data after top-level value")
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package helper
+
+import (
+	"cobaApp/customError"
+	"errors"
+	"fmt"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// custom rule for release date, every rule only accept string field
+var dateRules = map[string]validator.Func{
+	// date is YYYY-MM-DD and a real day of calendar
+	"date": func(fl validator.FieldLevel) bool {
+		_, err := ParseDate(fl.Field().String())
+		return err == nil
+	},
+	// min_year=1886 reject date before the year
+	"min_year": func(fl validator.FieldLevel) bool {
+		date, err := ParseDate(fl.Field().String())
+		minYear, paramErr := strconv.Atoi(fl.Param())
+		return err == nil && paramErr == nil && date.Year() >= minYear
+	},
+	// max_future_days=730 reject date later than the days from today
+	"max_future_days": func(fl validator.FieldLevel) bool {
+		date, err := ParseDate(fl.Field().String())
+		days, paramErr := strconv.Atoi(fl.Param())
+		return err == nil && paramErr == nil && !date.After(time.Now().AddDate(0, 0, days))
+	},
+}
+
+// message of custom rule, {0} is the field and {1} is the param
+var dateRuleMessages = map[string]map[string]string{
+	"en": {
+		"date":            "{0} must be a valid date with format YYYY-MM-DD",
+		"min_year":        "{0} must not be before year {1}",
+		"max_future_days": "{0} must not be more than {1} days from today",
+	},
+	"id": {
+		"date":            "{0} harus berupa tanggal yang valid dengan format YYYY-MM-DD",
+		"min_year":        "{0} tidak boleh sebelum tahun {1}",
+		"max_future_days": "{0} tidak boleh lebih dari {1} hari dari hari ini",
+	},
+}
+
+// NewValidator return validator shared by every service, field in error use the json name
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	for tag, fn := range dateRules {
+		// tag is fixed and fn is not nil, so it never fail
+		_ = validate.RegisterValidation(tag, fn)
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	return validate
+}
+
+// ValidationTranslator translate validator error to english or indonesian, english is the fallback
+type ValidationTranslator struct {
+	universal *ut.UniversalTranslator
+}
+
+// function provider, translation is registered to validate so only its error can be translated
+func NewValidationTranslator(validate *validator.Validate) (*ValidationTranslator, error) {
+	english := en.New()
+	universal := ut.New(english, english, id.New())
+
+	enTrans, _ := universal.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, err
+	}
+
+	idTrans, _ := universal.GetTranslator("id")
+	if err := idTranslations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		return nil, err
+	}
+
+	for locale, messages := range dateRuleMessages {
+		trans, _ := universal.GetTranslator(locale)
+		for tag, message := range messages {
+			if err := registerTranslation(validate, trans, tag, message); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &ValidationTranslator{universal: universal}, nil
+}
+
+func registerTranslation(validate *validator.Validate, trans ut.Translator, tag string, message string) error {
+	register := func(trans ut.Translator) error {
+		return trans.Add(tag, message, false)
+	}
+	translate := func(trans ut.Translator, fe validator.FieldError) string {
+		translated, err := trans.T(tag, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+		return translated
+	}
+
+	return validate.RegisterTranslation(tag, trans, register, translate)
+}
+
+// Translator pick translator from Accept-Language header, e.g. "id-ID,id;q=0.9,en;q=0.8"
+func (v *ValidationTranslator) Translator(acceptLanguage string) ut.Translator {
+	if v == nil {
+		return nil
+	}
+
+	type language struct {
+		tag string
+		q   float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		// id-ID match id
+		tag, _, _ = strings.Cut(strings.ToLower(tag), "-")
+		languages = append(languages, language{tag, q})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].q > languages[j].q
+	})
+
+	locales := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		if lang.q > 0 {
+			locales = append(locales, lang.tag)
+		}
+	}
+
+	translator, _ := v.universal.FindTranslator(locales...)
+	return translator
+}
+
+// ValidationErrorMessage flatten error from validator into one message
+func ValidationErrorMessage(err error) string {
+	return TranslateValidationError(err, nil)
+}
+
+// TranslateValidationError flatten error from validator into one message in the language of trans
+func TranslateValidationError(err error, trans ut.Translator) string {
+	details := ValidationErrorDetails(err, trans)
+	if details == nil {
+		return err.Error()
+	}
+
+	var errMessage []string
+	for _, detail := range details {
+		errMessage = append(errMessage, detail.Message)
+	}
+
+	return strings.Join(errMessage, ". ")
+}
+
+// ValidationErrorDetails return one detail per invalid field, nil when err is not from validator.
+// without trans or translation of the rule, message is "error on field [x] with tag [y]"
+func ValidationErrorDetails(err error, trans ut.Translator) []customError.Detail {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	details := make([]customError.Detail, 0, len(validationErrors))
+	for _, errorField := range validationErrors {
+		message := fmt.Sprintf("error on field [%v] with tag [%v]", errorField.Field(), errorField.ActualTag())
+		if trans != nil {
+			if translated := errorField.Translate(trans); translated != errorField.Error() {
+				message = translated
+			}
+		}
+
+		details = append(details, customError.Detail{
+			Field:   errorField.Field(),
+			Rule:    errorField.ActualTag(),
+			Param:   errorField.Param(),
+			Message: message,
+		})
+	}
+
+	return details
+}
@@ -1,14 +1,32 @@
 package logger
 
 import (
+	"cobaApp/config"
 	"github.com/sirupsen/logrus"
 	"os"
 )
 
-func NewConsoleLog() *logrus.Logger {
+func NewConsoleLog(cfg config.IConfig) *logrus.Logger {
 	log := logrus.New()
 	log.SetFormatter(&logrus.JSONFormatter{})
 	log.SetLevel(logrus.DebugLevel)
 	log.SetOutput(os.Stdout)
+
+	if level, err := logrus.ParseLevel(cfg.GetConfig().Log.Level); err == nil {
+		log.SetLevel(level)
+	} else {
+		log.Warnf("unknown log level [%v], use debug", cfg.GetConfig().Log.Level)
+	}
+
+	// apply new level on config reload
+	cfg.Subscribe(func(configApp *config.ConfigApp) {
+		level, err := logrus.ParseLevel(configApp.Log.Level)
+		if err != nil || level == log.GetLevel() {
+			return
+		}
+
+		log.SetLevel(level)
+		log.Infof("log level changed to [%v]", level)
+	})
 	return log
 }
@@ -2,39 +2,21 @@ package main
 
 import (
 	"cobaApp/config"
-	"cobaApp/database"
 	"cobaApp/logger"
-	"cobaApp/server"
-	tracing "cobaApp/tracing"
 	"fmt"
-	"github.com/opentracing/opentracing-go"
+	"os"
 )
 
 func main() {
-	fmt.Println("haloo semua")
-
 	// load config
-	cfg := config.NewConfig()
-
-	fmt.Println(cfg.GetConfig().Database.Name)
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	// define log console
-	log := logger.NewConsoleLog()
-
-	// define tracing
-	tracer, closer := tracing.GenerateTracing(cfg, log, "cobaApp")
-	defer closer.Close()
+	log := logger.NewConsoleLog(cfg)
 
-	opentracing.SetGlobalTracer(tracer)
-
-	// connect to database
-	db := database.ConnectDatabase(cfg, log)
-	defer db.Close()
-
-	appServer := server.NewAppServer(db, cfg, log)
-	if err := appServer.RunServer(); err != nil {
-		log.Fatalf("cant start app : %v", err)
-	} else {
-		log.Info("success run app!")
-	}
+	os.Exit(runCommand(cfg, log, cfg.Args()))
 }
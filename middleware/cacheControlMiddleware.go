@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"cobaApp/config"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// CacheControlMiddleware set Cache-Control on cacheable read responses, unless the handler already set it
+func CacheControlMiddleware(cfg config.IConfig) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		err := ctx.Next()
+
+		method := ctx.Method()
+		if method != http.MethodGet && method != http.MethodHead {
+			return err
+		}
+
+		statusCode := ctx.Response().StatusCode()
+		if statusCode != http.StatusOK && statusCode != http.StatusNotModified {
+			return err
+		}
+
+		if len(ctx.Response().Header.Peek(fiber.HeaderCacheControl)) > 0 {
+			return err
+		}
+
+		if httpConfig := cfg.GetConfig().Http; httpConfig != nil && httpConfig.CacheControl != "" {
+			ctx.Set(fiber.HeaderCacheControl, httpConfig.CacheControl)
+		}
+		return err
+	}
+}
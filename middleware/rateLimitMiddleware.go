@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"cobaApp/config"
+	"cobaApp/customError"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// rateLimiter count request per key in fixed window
+type rateLimiter struct {
+	mutex     sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+// allow count one request and return the remaining quota and when the window end
+func (r *rateLimiter) allow(key string, max int, window time.Duration, now time.Time) (bool, int, time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// drop finished windows so the map not grow forever
+	if now.Sub(r.lastSweep) >= window {
+		for k, w := range r.windows {
+			if now.Sub(w.start) >= window {
+				delete(r.windows, k)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	w, ok := r.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		r.windows[key] = w
+	}
+
+	w.count++
+	return w.count <= max, max - w.count, w.start.Add(window)
+}
+
+// RateLimitMiddleware limit request per client ip, limit and window are read every request so reload apply live
+func RateLimitMiddleware(cfg config.IConfig) fiber.Handler {
+	limiter := &rateLimiter{windows: map[string]*rateWindow{}}
+
+	return func(ctx *fiber.Ctx) error {
+		rateLimit := cfg.GetConfig().RateLimit
+		if rateLimit == nil || !rateLimit.Enabled {
+			return ctx.Next()
+		}
+
+		now := time.Now()
+		allowed, remaining, reset := limiter.allow(ctx.IP(), rateLimit.Max, rateLimit.Window, now)
+
+		ctx.Set("X-RateLimit-Limit", strconv.Itoa(rateLimit.Max))
+		ctx.Set("X-RateLimit-Remaining", strconv.Itoa(max(remaining, 0)))
+		if allowed {
+			return ctx.Next()
+		}
+
+		retryAfter := int(math.Ceil(reset.Sub(now).Seconds()))
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+		return customError.NewTooManyRequestsError(fmt.Sprintf("too many requests, try again in %v seconds", retryAfter))
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"cobaApp/config"
+	"cobaApp/database"
+	"cobaApp/migration"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate handle `cobaApp migrate ...` and return the process exit code
+func runMigrate(cfg config.IConfig, log *logrus.Logger, args []string) int {
+	if len(args) == 0 {
+		return usageError("migrate")
+	}
+
+	version := 0
+	switch args[0] {
+	case "up", "down", "status":
+		if len(args) != 1 {
+			return usageError("migrate")
+		}
+	case "to":
+		if len(args) != 2 {
+			return usageError("migrate")
+		}
+
+		var err error
+		if version, err = strconv.Atoi(args[1]); err != nil || version < 0 {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", args[1])
+			return 2
+		}
+	default:
+		return usageError("migrate")
+	}
+
+	migrations, err := migration.Embedded()
+	if err != nil {
+		log.Errorf("cant load migration : %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	db, err := database.ConnectDatabase(ctx, cfg, log)
+	if err != nil {
+		log.Errorf("cant connect database : %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator := migration.NewMigrator(db, migrations, log)
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx)
+		if err != nil {
+			log.Errorf("cant migrate up : %v", err)
+			return 1
+		}
+		log.Infof("success apply %v migration", len(done))
+	case "down":
+		done, err := migrator.Down(ctx)
+		if err != nil {
+			log.Errorf("cant migrate down : %v", err)
+			return 1
+		}
+		if done == nil {
+			log.Info("no migration to roll back")
+		}
+	case "to":
+		done, err := migrator.To(ctx, version)
+		if err != nil {
+			log.Errorf("cant migrate to %v : %v", version, err)
+			return 1
+		}
+		log.Infof("success run %v migration", len(done))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Errorf("cant get migration status : %v", err)
+			return 1
+		}
+		printMigrationStatus(statuses)
+	}
+
+	return 0
+}
+
+func printMigrationStatus(statuses []migration.Status) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%04d\t%v\t%v\n", status.Version, status.Name, appliedAt)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,10 @@
+package migration
+
+import "context"
+
+type IMigrator interface {
+	Up(ctx context.Context) ([]Migration, error)
+	Down(ctx context.Context) (*Migration, error)
+	To(ctx context.Context, version int) ([]Migration, error)
+	Status(ctx context.Context) ([]Status, error)
+}
@@ -0,0 +1,182 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint not null primary key,
+    name varchar(255) not null,
+    applied_at timestamp not null default current_timestamp
+)engine=InnoDB`
+
+// Migrator apply migrations one by one. mysql commit DDL implicitly, so a failed migration
+// is not rolled back and the version is only recorded after every statement success
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+	Log        *logrus.Logger
+}
+
+// function provider
+func NewMigrator(db *sql.DB, migrations []Migration, log *logrus.Logger) IMigrator {
+	return &Migrator{
+		DB:         db,
+		Migrations: migrations,
+		Log:        log,
+	}
+}
+
+// Up apply every pending migration
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if len(m.Migrations) == 0 {
+		return nil, nil
+	}
+
+	return m.To(ctx, m.Migrations[len(m.Migrations)-1].Version)
+}
+
+// Down roll back only the last applied migration
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[m.Migrations[i].Version]; ok {
+			if err := m.down(ctx, m.Migrations[i]); err != nil {
+				return nil, err
+			}
+			return &m.Migrations[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// To apply or roll back until version is the last applied, version 0 roll back everything
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("migration version %v not found", version)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	// roll back newer first
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		migration := m.Migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > version {
+			if err := m.down(ctx, migration); err != nil {
+				return done, err
+			}
+			done = append(done, migration)
+		}
+	}
+
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+			if err := m.up(ctx, migration); err != nil {
+				return done, err
+			}
+			done = append(done, migration)
+		}
+	}
+
+	return done, nil
+}
+
+// Status list every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied, status.AppliedAt = true, &appliedAt
+		}
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+func (m *Migrator) up(ctx context.Context, migration Migration) error {
+	m.Log.Infof("apply migration %04d_%v", migration.Version, migration.Name)
+
+	if err := m.exec(ctx, migration, migration.Up); err != nil {
+		return err
+	}
+
+	_, err := m.DB.ExecContext(ctx, "INSERT INTO schema_migrations(version, name) VALUES (?, ?)", migration.Version, migration.Name)
+	return err
+}
+
+func (m *Migrator) down(ctx context.Context, migration Migration) error {
+	m.Log.Infof("roll back migration %04d_%v", migration.Version, migration.Name)
+
+	if err := m.exec(ctx, migration, migration.Down); err != nil {
+		return err
+	}
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version=?", migration.Version)
+	return err
+}
+
+func (m *Migrator) exec(ctx context.Context, migration Migration, script string) error {
+	for i, statement := range statements(script) {
+		if _, err := m.DB.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %04d_%v failed on statement %v : %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// applied return applied version with the time it was applied
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	if _, err := m.DB.ExecContext(ctx, createTable); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.Migrations {
+		if m.Migrations[i].Version == version {
+			return &m.Migrations[i]
+		}
+	}
+
+	return nil
+}
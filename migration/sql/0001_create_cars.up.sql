@@ -0,0 +1,10 @@
+-- baseline schema from database/init.sql, database created before migration already has it
+CREATE TABLE IF NOT EXISTS `cars` (
+    id int not null primary key AUTO_INCREMENT,
+    name varchar(255) not null ,
+    price DECIMAL(20, 3) NOT NULL DEFAULT 0.000,
+    release_date timestamp not null default current_timestamp
+)engine=InnoDB;
+
+-- seed only fresh table
+INSERT INTO cars(name, price) SELECT 'Toyota Innova Zenix Q', 614000000 FROM DUAL WHERE NOT EXISTS (SELECT 1 FROM cars);
@@ -0,0 +1,4 @@
+ALTER TABLE `cars`
+    DROP INDEX idx_cars_name_id,
+    DROP INDEX idx_cars_price_id,
+    DROP INDEX idx_cars_release_date_id;
@@ -0,0 +1,4 @@
+ALTER TABLE `cars`
+    ADD INDEX idx_cars_name_id (name, id),
+    ADD INDEX idx_cars_price_id (price, id),
+    ADD INDEX idx_cars_release_date_id (release_date, id);
@@ -0,0 +1,3 @@
+ALTER TABLE `cars`
+    DROP INDEX idx_cars_deleted_at,
+    DROP deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE `cars`
+    ADD deleted_at timestamp null default null,
+    ADD INDEX idx_cars_deleted_at (deleted_at);
@@ -0,0 +1,3 @@
+ALTER TABLE `cars`
+    DROP updated_at,
+    DROP version;
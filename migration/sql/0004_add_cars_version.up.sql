@@ -0,0 +1,3 @@
+ALTER TABLE `cars`
+    ADD version int not null default 1,
+    ADD updated_at timestamp not null default current_timestamp on update current_timestamp;
@@ -0,0 +1,4 @@
+ALTER TABLE `cars`
+    DROP INDEX uq_cars_name_release_day_alive,
+    DROP alive,
+    DROP release_day;
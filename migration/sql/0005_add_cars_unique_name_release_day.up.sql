@@ -0,0 +1,7 @@
+-- one car per name and release day. alive is NULL for soft deleted car and unique index allow many NULL,
+-- so trashed car keep its name. id can not be used instead, generated column can not refer to AUTO_INCREMENT.
+-- existing duplicate must be renamed or trashed before this migration
+ALTER TABLE `cars`
+    ADD release_day date GENERATED ALWAYS AS (DATE(release_date)) VIRTUAL,
+    ADD alive tinyint GENERATED ALWAYS AS (IF(deleted_at IS NULL, 1, NULL)) VIRTUAL,
+    ADD UNIQUE INDEX uq_cars_name_release_day_alive (name, release_day, alive);
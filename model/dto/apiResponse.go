@@ -1,8 +1,13 @@
 package dto
 
+import "cobaApp/customError"
+
 type ApiResponse struct {
-	StatusCode int    `json:"status_code"`
-	Status     string `json:"status"`
-	Message    string `json:"message"`
-	Data       any    `json:"data"`
+	StatusCode int                  `json:"status_code"`
+	Status     string               `json:"status"`
+	Code       string               `json:"code,omitempty"`
+	Message    string               `json:"message"`
+	Details    []customError.Detail `json:"details,omitempty"`
+	Data       any                  `json:"data"`
+	Meta       *PageMeta            `json:"meta,omitempty"`
 }
@@ -0,0 +1,23 @@
+package dto
+
+import "cobaApp/customError"
+
+type BulkInsertCarResponse struct {
+	Mode    string                `json:"mode"`
+	Total   int                   `json:"total"`
+	Success int                   `json:"success"`
+	Failed  int                   `json:"failed"`
+	Results []BulkInsertCarResult `json:"results"`
+}
+
+type BulkInsertCarResult struct {
+	Index   int                  `json:"index"`
+	Line    int                  `json:"line,omitempty"`
+	Success bool                 `json:"success"`
+	Data    *InsertCarResponse   `json:"data,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	Details []customError.Detail `json:"details,omitempty"`
+
+	// ValidationError is kept so handler can translate Error and Details to the client language
+	ValidationError error `json:"-"`
+}
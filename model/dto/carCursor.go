@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// CarCursor is the seek position (sort key, id) carried inside the signed cursor.
+// sort key keep the column type so it is bound as is, without cast in database
+type CarCursor struct {
+	Sort        string     `json:"s"`
+	Order       string     `json:"o"`
+	Name        string     `json:"n,omitempty"`
+	Price       float64    `json:"p,omitempty"`
+	ReleaseDate *time.Time `json:"d,omitempty"`
+	Id          int        `json:"i"`
+	Backward    bool       `json:"b,omitempty"`
+
+	// Filter is the fingerprint of filters the cursor was created with
+	Filter string `json:"f"`
+}
+
+// SeekValue return value of the sort key
+func (c *CarCursor) SeekValue() any {
+	switch c.Sort {
+	case "name":
+		return c.Name
+	case "price":
+		return c.Price
+	case "release_date":
+		if c.ReleaseDate == nil {
+			return time.Time{}
+		}
+		return *c.ReleaseDate
+	default:
+		return c.Id
+	}
+}
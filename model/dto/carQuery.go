@@ -0,0 +1,23 @@
+package dto
+
+// CarQuery is the query spec for listing cars, parsed from query string in handler
+// and passed down to service and repository
+type CarQuery struct {
+	Page        int     `json:"page" query:"page" validate:"omitempty,min=1"`
+	Limit       int     `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Sort        string  `json:"sort" query:"sort" validate:"omitempty,oneof=id name price release_date"`
+	Order       string  `json:"order" query:"order" validate:"omitempty,oneof=asc desc"`
+	Name        string  `json:"name" query:"name"`
+	MinPrice    float64 `json:"min_price" query:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice    float64 `json:"max_price" query:"max_price" validate:"omitempty,gte=0"`
+	ReleaseFrom string  `json:"release_from" query:"release_from" validate:"omitempty,datetime=2006-01-02"`
+	ReleaseTo   string  `json:"release_to" query:"release_to" validate:"omitempty,datetime=2006-01-02"`
+	Pagination  string  `json:"pagination" query:"pagination" validate:"omitempty,oneof=offset cursor"`
+	Cursor      string  `json:"cursor" query:"cursor"`
+
+	// decoded from Cursor by service, used by repository to seek
+	After *CarCursor `json:"after,omitempty" query:"-"`
+
+	// list soft deleted data instead of active data
+	Trashed bool `json:"trashed,omitempty" query:"-"`
+}
@@ -1,7 +1,8 @@
 package dto
 
+// release date is between the first car (1886) and two years from today
 type InsertCarRequest struct {
-	Name        string  `json:"name" validate:"required"`
+	Name        string  `json:"name" validate:"required,max=255"`
 	Price       float64 `json:"price" validate:"required,gt=0.000"`
-	ReleaseDate string  `json:"release_date" validate:"required"`
+	ReleaseDate string  `json:"release_date" validate:"required,date,min_year=1886,max_future_days=730"`
 }
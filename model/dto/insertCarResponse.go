@@ -5,4 +5,7 @@ type InsertCarResponse struct {
 	Name        string  `json:"name"`
 	Price       float64 `json:"price"`
 	ReleaseDate string  `json:"release_date"`
+	DeletedAt   string  `json:"deleted_at,omitempty"`
+	Version     int     `json:"version"`
+	UpdatedAt   string  `json:"updated_at,omitempty"`
 }
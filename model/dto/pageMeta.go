@@ -0,0 +1,12 @@
+package dto
+
+type PageMeta struct {
+	Page       int     `json:"page,omitempty"`
+	Limit      int     `json:"limit"`
+	TotalData  int     `json:"total_data,omitempty"`
+	TotalPage  int     `json:"total_page,omitempty"`
+	NextPage   *int    `json:"next_page,omitempty"`
+	PrevPage   *int    `json:"prev_page,omitempty"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
+}
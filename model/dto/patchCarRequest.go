@@ -0,0 +1,8 @@
+package dto
+
+// field yang nil tidak akan diubah
+type PatchCarRequest struct {
+	Name        *string  `json:"name" validate:"omitnil,min=1,max=255"`
+	Price       *float64 `json:"price" validate:"omitnil,gt=0.000"`
+	ReleaseDate *string  `json:"release_date" validate:"omitnil,date,min_year=1886,max_future_days=730"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "cobaApp/customError"
+
+// ProblemResponse is RFC 7807 problem document, code, errors and data are extension member
+type ProblemResponse struct {
+	Type     string               `json:"type"`
+	Title    string               `json:"title"`
+	Status   int                  `json:"status"`
+	Detail   string               `json:"detail,omitempty"`
+	Instance string               `json:"instance,omitempty"`
+	Code     string               `json:"code,omitempty"`
+	Errors   []customError.Detail `json:"errors,omitempty"`
+	Data     any                  `json:"data,omitempty"`
+}
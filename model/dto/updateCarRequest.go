@@ -0,0 +1,7 @@
+package dto
+
+type UpdateCarRequest struct {
+	Name        string  `json:"name" validate:"required,max=255"`
+	Price       float64 `json:"price" validate:"required,gt=0.000"`
+	ReleaseDate string  `json:"release_date" validate:"required,date,min_year=1886,max_future_days=730"`
+}
@@ -9,4 +9,14 @@ type Car struct {
 	Name        string        `json:"name"`
 	Price       float64       `json:"price"`
 	ReleaseDate *sql.NullTime `json:"release_date"`
+	DeletedAt   *sql.NullTime `json:"deleted_at"`
+	Version     int           `json:"version"`
+	UpdatedAt   *sql.NullTime `json:"updated_at"`
+}
+
+// CarPatch hold the columns to be changed by partial update, nil means unchanged
+type CarPatch struct {
+	Name        *string       `json:"name,omitempty"`
+	Price       *float64      `json:"price,omitempty"`
+	ReleaseDate *sql.NullTime `json:"release_date,omitempty"`
 }
@@ -1,13 +1,27 @@
 package repository
 
 import (
+	"cobaApp/model/dto"
 	"cobaApp/model/entity"
 	"context"
 	"database/sql"
+	"time"
 )
 
 type ICarRepository interface {
 	Insert(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error)
-	GetAll(ctx context.Context, tx *sql.Tx) ([]entity.Car, error)
+	GetAll(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) ([]entity.Car, error)
+	Count(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) (int, error)
+	LastModified(ctx context.Context, tx *sql.Tx) (time.Time, error)
+	Iterate(ctx context.Context, tx *sql.Tx, fn func(car *entity.Car) error) error
 	GetDetail(ctx context.Context, tx *sql.Tx, id int) (*entity.Car, error)
+	IsDuplicate(ctx context.Context, tx *sql.Tx, name string, releaseDate time.Time, excludeId int) (bool, error)
+	Update(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error)
+	Patch(ctx context.Context, tx *sql.Tx, id int, version int, input *entity.CarPatch) error
+	Delete(ctx context.Context, tx *sql.Tx, id int, version int) error
+	Restore(ctx context.Context, tx *sql.Tx, id int) error
+	Purge(ctx context.Context, tx *sql.Tx, before time.Time) (int, error)
+
+	// Invalidate drop cached cars after the transaction that changed them committed
+	Invalidate(ctx context.Context, ids ...int)
 }
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"cobaApp/cache"
+	"cobaApp/config"
+	"cobaApp/model/dto"
+	"cobaApp/model/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL = time.Minute
+
+	// list style key share one prefix, so every write can drop them at once
+	carListKeyPrefix = "cars:"
+)
+
+var (
+	carCacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "car_cache_hits_total",
+		Help: "Number of car repository reads served from cache.",
+	}, []string{"operation"})
+	carCacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "car_cache_misses_total",
+		Help: "Number of car repository reads that went to the database.",
+	}, []string{"operation"})
+)
+
+// CarCacheRepository is read-through cache in front of another ICarRepository.
+// only reads outside transaction use the cache, a transaction may see its own uncommitted write.
+// write inside transaction is dropped from cache by Invalidate after the commit
+type CarCacheRepository struct {
+	CarRepository ICarRepository
+	Cache         cache.ICache
+	Config        config.IConfig
+
+	// generation grow on every invalidation, a read only store its result
+	// when nothing was invalidated since it started
+	mutex      sync.Mutex
+	generation uint64
+}
+
+// function provider
+func NewCarCacheRepository(carRepo ICarRepository, cache cache.ICache, cfg config.IConfig) ICarRepository {
+	return &CarCacheRepository{
+		CarRepository: carRepo,
+		Cache:         cache,
+		Config:        cfg,
+	}
+}
+
+func (c *CarCacheRepository) Insert(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error) {
+	defer c.invalidateAutocommit(ctx, tx)
+	return c.CarRepository.Insert(ctx, tx, input)
+}
+
+func (c *CarCacheRepository) GetAll(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) ([]entity.Car, error) {
+	if tx != nil {
+		return c.CarRepository.GetAll(ctx, tx, query)
+	}
+
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Cache GetAll")
+	defer span.Finish()
+
+	key, generation := carQueryKey("list", query), c.currentGeneration()
+
+	var cars []entity.Car
+	if c.load(ctxTracing, span, "get_all", key, &cars) {
+		return cars, nil
+	}
+
+	cars, err := c.CarRepository.GetAll(ctxTracing, tx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(ctxTracing, span, generation, key, cars)
+	return cars, nil
+}
+
+func (c *CarCacheRepository) Count(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) (int, error) {
+	if tx != nil {
+		return c.CarRepository.Count(ctx, tx, query)
+	}
+
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Cache Count")
+	defer span.Finish()
+
+	key, generation := carQueryKey("count", query), c.currentGeneration()
+
+	var total int
+	if c.load(ctxTracing, span, "count", key, &total) {
+		return total, nil
+	}
+
+	total, err := c.CarRepository.Count(ctxTracing, tx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	c.store(ctxTracing, span, generation, key, total)
+	return total, nil
+}
+
+func (c *CarCacheRepository) LastModified(ctx context.Context, tx *sql.Tx) (time.Time, error) {
+	if tx != nil {
+		return c.CarRepository.LastModified(ctx, tx)
+	}
+
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Cache LastModified")
+	defer span.Finish()
+
+	key, generation := carListKeyPrefix+"last_modified", c.currentGeneration()
+
+	var lastModified time.Time
+	if c.load(ctxTracing, span, "last_modified", key, &lastModified) {
+		return lastModified, nil
+	}
+
+	lastModified, err := c.CarRepository.LastModified(ctxTracing, tx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c.store(ctxTracing, span, generation, key, lastModified)
+	return lastModified, nil
+}
+
+// Iterate is used by export to stream the whole table, not worth to cache
+func (c *CarCacheRepository) Iterate(ctx context.Context, tx *sql.Tx, fn func(car *entity.Car) error) error {
+	return c.CarRepository.Iterate(ctx, tx, fn)
+}
+
+func (c *CarCacheRepository) GetDetail(ctx context.Context, tx *sql.Tx, id int) (*entity.Car, error) {
+	if tx != nil {
+		return c.CarRepository.GetDetail(ctx, tx, id)
+	}
+
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Cache GetDetail")
+	defer span.Finish()
+
+	key, generation := carDetailKey(id), c.currentGeneration()
+
+	var car entity.Car
+	if c.load(ctxTracing, span, "get_detail", key, &car) {
+		return &car, nil
+	}
+
+	result, err := c.CarRepository.GetDetail(ctxTracing, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(ctxTracing, span, generation, key, result)
+	return result, nil
+}
+
+func (c *CarCacheRepository) Update(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error) {
+	defer c.invalidateAutocommit(ctx, tx, input.Id)
+	return c.CarRepository.Update(ctx, tx, input)
+}
+
+func (c *CarCacheRepository) Patch(ctx context.Context, tx *sql.Tx, id int, version int, input *entity.CarPatch) error {
+	defer c.invalidateAutocommit(ctx, tx, id)
+	return c.CarRepository.Patch(ctx, tx, id, version, input)
+}
+
+func (c *CarCacheRepository) Delete(ctx context.Context, tx *sql.Tx, id int, version int) error {
+	defer c.invalidateAutocommit(ctx, tx, id)
+	return c.CarRepository.Delete(ctx, tx, id, version)
+}
+
+// IsDuplicate guard a write, so it always read the database
+func (c *CarCacheRepository) IsDuplicate(ctx context.Context, tx *sql.Tx, name string, releaseDate time.Time, excludeId int) (bool, error) {
+	return c.CarRepository.IsDuplicate(ctx, tx, name, releaseDate, excludeId)
+}
+
+func (c *CarCacheRepository) Restore(ctx context.Context, tx *sql.Tx, id int) error {
+	defer c.invalidateAutocommit(ctx, tx, id)
+	return c.CarRepository.Restore(ctx, tx, id)
+}
+
+func (c *CarCacheRepository) Purge(ctx context.Context, tx *sql.Tx, before time.Time) (int, error) {
+	defer c.invalidateAutocommit(ctx, tx)
+	return c.CarRepository.Purge(ctx, tx, before)
+}
+
+// load read key into target, cache error is treated as miss so the database still answer
+func (c *CarCacheRepository) load(ctx context.Context, span opentracing.Span, operation string, key string, target any) bool {
+	value, err := c.Cache.Get(ctx, key)
+	if err == nil {
+		err = json.Unmarshal(value, target)
+	}
+
+	if err != nil {
+		if !errors.Is(err, cache.ErrCacheMiss) {
+			span.LogFields(log.String("cache_error", err.Error()))
+		}
+		span.LogFields(log.Bool("cache_hit", false))
+		carCacheMisses.WithLabelValues(operation).Inc()
+		return false
+	}
+
+	span.LogFields(log.Bool("cache_hit", true))
+	carCacheHits.WithLabelValues(operation).Inc()
+	return true
+}
+
+// store save value unless something was invalidated after the read started, the value may be older than the commit
+func (c *CarCacheRepository) store(ctx context.Context, span opentracing.Span, generation uint64, key string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		span.LogFields(log.String("cache_error", err.Error()))
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		span.LogFields(log.Bool("cache_stale", true))
+		return
+	}
+
+	if err := c.Cache.Set(ctx, key, data, c.ttl()); err != nil {
+		span.LogFields(log.String("cache_error", err.Error()))
+	}
+}
+
+func (c *CarCacheRepository) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// Invalidate drop every list key and the detail of the given ids
+func (c *CarCacheRepository) Invalidate(ctx context.Context, ids ...int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.Cache.DeletePrefix(ctx, carListKeyPrefix)
+
+	if len(ids) > 0 {
+		keys := make([]string, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys, carDetailKey(id))
+		}
+		c.Cache.Delete(ctx, keys...)
+	}
+}
+
+// invalidateAutocommit drop cache of write without transaction, it is already committed.
+// write inside transaction wait for Invalidate after commit
+func (c *CarCacheRepository) invalidateAutocommit(ctx context.Context, tx *sql.Tx, ids ...int) {
+	if tx == nil {
+		c.Invalidate(ctx, ids...)
+	}
+}
+
+func (c *CarCacheRepository) ttl() time.Duration {
+	if cacheConfig := c.Config.GetConfig().Cache; cacheConfig != nil && cacheConfig.TTL > 0 {
+		return cacheConfig.TTL
+	}
+
+	return defaultCacheTTL
+}
+
+func carDetailKey(id int) string {
+	return fmt.Sprintf("car:%v", id)
+}
+
+func carQueryKey(kind string, query *dto.CarQuery) string {
+	queryJson, _ := json.Marshal(query)
+	return fmt.Sprintf("%v%v:%s", carListKeyPrefix, kind, queryJson)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"fmt"
+	"strings"
+)
+
+// whitelist sort key from query spec to column name
+var carSortColumns = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"price":        "price",
+	"release_date": "release_date",
+}
+
+// likeEscaper escape LIKE wildcard and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// build where clause and the arguments from query spec
+func carFilter(query *dto.CarQuery) (string, []any) {
+	// soft deleted data only visible from trash
+	conditions := []string{"deleted_at IS NULL"}
+	if query.Trashed {
+		conditions = []string{"deleted_at IS NOT NULL"}
+	}
+	var args []any
+
+	if query.Name != "" {
+		// name is matched literally, wildcard in user input is escaped
+		conditions = append(conditions, `name LIKE ? ESCAPE '\\'`)
+		args = append(args, "%"+likeEscaper.Replace(query.Name)+"%")
+	}
+	if query.MinPrice > 0 {
+		conditions = append(conditions, "price >= ?")
+		args = append(args, query.MinPrice)
+	}
+	if query.MaxPrice > 0 {
+		conditions = append(conditions, "price <= ?")
+		args = append(args, query.MaxPrice)
+	}
+	if query.ReleaseFrom != "" {
+		conditions = append(conditions, "release_date >= ?")
+		args = append(args, helper.StringToDate(query.ReleaseFrom))
+	}
+	if query.ReleaseTo != "" {
+		// release_to is inclusive, so compare with the next day
+		conditions = append(conditions, "release_date < ?")
+		args = append(args, helper.StringToDate(query.ReleaseTo).AddDate(0, 0, 1))
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// add keyset condition (sort key, id) after the cursor position to where clause
+func carSeek(where string, args []any, cursor *dto.CarCursor) (string, []any) {
+	column := carSortColumns[cursor.Sort]
+
+	operator := ">"
+	if cursor.Order == "desc" {
+		operator = "<"
+	}
+	if cursor.Backward {
+		operator = map[string]string{">": "<", "<": ">"}[operator]
+	}
+
+	condition := fmt.Sprintf("(%v %v ? OR (%v = ? AND id %v ?))", column, operator, column, operator)
+	value := cursor.SeekValue()
+	args = append(args, value, value, cursor.Id)
+
+	return where + " AND " + condition, args
+}
+
+func reverseOrder(order string) string {
+	if order == "desc" {
+		return "asc"
+	}
+
+	return "desc"
+}
@@ -2,12 +2,19 @@ package repository
 
 import (
 	"cobaApp/customError"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
 	"cobaApp/model/entity"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
+	"strings"
+	"time"
 )
 
 type CarRepository struct {
@@ -21,6 +28,24 @@ func NewCarRepository(db *sql.DB) ICarRepository {
 	}
 }
 
+// prepare statement in tx, or directly in database when there is no tx
+func (c *CarRepository) prepare(ctx context.Context, tx *sql.Tx, query string) (*sql.Stmt, error) {
+	if tx == nil {
+		return c.DB.PrepareContext(ctx, query)
+	}
+
+	return tx.PrepareContext(ctx, query)
+}
+
+// queryRow query in tx, or directly in database when there is no tx
+func (c *CarRepository) queryRow(ctx context.Context, tx *sql.Tx, query string, args ...any) *sql.Row {
+	if tx == nil {
+		return c.DB.QueryRowContext(ctx, query, args...)
+	}
+
+	return tx.QueryRowContext(ctx, query, args...)
+}
+
 // method implementasi Insert
 func (c *CarRepository) Insert(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error) {
 	// start tracing
@@ -30,54 +55,88 @@ func (c *CarRepository) Insert(ctx context.Context, tx *sql.Tx, input *entity.Ca
 	reqString, _ := json.Marshal(&input)
 	span.LogFields(log.String("request", string(reqString)))
 
+	// release_date omitted when empty so the column default is used
+	query := "INSERT INTO cars(name, price, release_date) VALUES (?, ?, ?)"
+	args := []any{input.Name, input.Price}
+	if input.ReleaseDate != nil && input.ReleaseDate.Valid {
+		args = append(args, input.ReleaseDate.Time)
+	} else {
+		query = "INSERT INTO cars(name, price) VALUES (?, ?)"
+	}
+
 	// prepare query
-	statement, err := tx.PrepareContext(ctxTracing, "INSERT INTO cars(name, price, release_date) VALUES (?, ?, ?)")
+	statement, err := c.prepare(ctxTracing, tx, query)
 	if err != nil {
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 	}
+	defer statement.Close()
 
-	result, err := statement.ExecContext(ctxTracing, input.Name, input.Price, input.ReleaseDate.Time)
+	result, err := statement.ExecContext(ctxTracing, args...)
 	if err != nil {
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, writeError(err)
 	}
 
-	id, err := result.RowsAffected()
+	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 	}
 
-	// success insert
-	input.Id = int(id)
-	return input, nil
+	// re-read stored row, so rounding and default value from database are returned
+	return c.GetDetail(ctxTracing, tx, int(id))
 }
 
 // method implementasi GetAll
-func (c *CarRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]entity.Car, error) {
+func (c *CarRepository) GetAll(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) ([]entity.Car, error) {
 	// start tracing
 	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository GetAll")
 	defer span.Finish()
 
+	reqJson, _ := json.Marshal(&query)
+	span.LogFields(log.String("request", string(reqJson)))
+
+	// build query from query spec
+	where, args := carFilter(query)
+	column, order := carSortColumns[query.Sort], query.Order
+
+	var sqlQuery string
+	if query.After != nil {
+		// keyset pagination, seek on (sort key, id) instead of OFFSET
+		where, args = carSeek(where, args, query.After)
+		if query.After.Backward {
+			order = reverseOrder(order)
+		}
+		sqlQuery = fmt.Sprintf("SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars%v ORDER BY %v %v, id %v LIMIT ?",
+			where, column, order, order)
+		args = append(args, query.Limit)
+	} else {
+		sqlQuery = fmt.Sprintf("SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars%v ORDER BY %v %v, id %v LIMIT ? OFFSET ?",
+			where, column, order, order)
+		args = append(args, query.Limit, (query.Page-1)*query.Limit)
+	}
+
 	// prepare query
-	statement, err := tx.PrepareContext(ctxTracing, "SELECT id, name, price, release_date FROM cars")
+	statement, err := c.prepare(ctxTracing, tx, sqlQuery)
 	if err != nil {
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 	}
+	defer statement.Close()
 
 	// execute query
-	rows, err := statement.QueryContext(ctxTracing)
+	rows, err := statement.QueryContext(ctxTracing, args...)
 	if err != nil {
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 	}
+	defer rows.Close()
 
 	var response []entity.Car
 	for rows.Next() {
 		var res entity.Car
-		if err := rows.Scan(&res.Id, &res.Name, &res.Price, &res.ReleaseDate); err != nil {
+		if err := rows.Scan(&res.Id, &res.Name, &res.Price, &res.ReleaseDate, &res.DeletedAt, &res.Version, &res.UpdatedAt); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, customError.NewNotFoundError("record not found")
 			}
 
-			return nil, customError.NewInternalServerError(err.Error())
+			return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 		}
 
 		response = append(response, res)
@@ -88,6 +147,13 @@ func (c *CarRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]entity.Car, e
 		return nil, customError.NewNotFoundError("record not found")
 	}
 
+	// backward seek read in reverse order, flip it back
+	if query.After != nil && query.After.Backward {
+		for i, j := 0, len(response)-1; i < j; i, j = i+1, j-1 {
+			response[i], response[j] = response[j], response[i]
+		}
+	}
+
 	// log response to tracing
 	resJson, _ := json.Marshal(&response)
 	span.LogFields(log.String("response", string(resJson)))
@@ -96,6 +162,102 @@ func (c *CarRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]entity.Car, e
 	return response, nil
 }
 
+// method implementasi count data sesuai filter
+func (c *CarRepository) Count(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) (int, error) {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Count")
+	defer span.Finish()
+
+	where, args := carFilter(query)
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx, "SELECT COUNT(id) FROM cars"+where)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	var total int
+	if err := statement.QueryRowContext(ctxTracing, args...).Scan(&total); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	span.LogFields(log.Int("total", total))
+	return total, nil
+}
+
+// method implementasi waktu perubahan terakhir, data terhapus ikut dihitung karena delete juga mengubah list
+func (c *CarRepository) LastModified(ctx context.Context, tx *sql.Tx) (time.Time, error) {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository LastModified")
+	defer span.Finish()
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx, "SELECT MAX(updated_at) FROM cars")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return time.Time{}, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	var lastModified sql.NullTime
+	if err := statement.QueryRowContext(ctxTracing).Scan(&lastModified); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return time.Time{}, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	span.LogFields(log.String("last_modified", lastModified.Time.Format(time.RFC3339)))
+	return lastModified.Time, nil
+}
+
+// method implementasi baca semua data satu per satu tanpa menampung semuanya di memory
+func (c *CarRepository) Iterate(ctx context.Context, tx *sql.Tx, fn func(car *entity.Car) error) error {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Iterate")
+	defer span.Finish()
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx, "SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	// execute query
+	rows, err := statement.QueryContext(ctxTracing)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer rows.Close()
+
+	var total int
+	for rows.Next() {
+		var car entity.Car
+		if err := rows.Scan(&car.Id, &car.Name, &car.Price, &car.ReleaseDate, &car.DeletedAt, &car.Version, &car.UpdatedAt); err != nil {
+			span.LogFields(log.String("error", err.Error()))
+			return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+		}
+
+		if err := fn(&car); err != nil {
+			span.LogFields(log.String("error", err.Error()))
+			return err
+		}
+		total++
+	}
+
+	if err := rows.Err(); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	span.LogFields(log.Int("total", total))
+	return nil
+}
+
 // method implementasi get detail by id
 func (c *CarRepository) GetDetail(ctx context.Context, tx *sql.Tx, id int) (*entity.Car, error) {
 	// start span tracing
@@ -106,11 +268,12 @@ func (c *CarRepository) GetDetail(ctx context.Context, tx *sql.Tx, id int) (*ent
 	span.LogFields(log.Int("id", id))
 
 	// prepare query
-	statement, err := tx.PrepareContext(ctxTracing, "SELECT id, name, price, release_date FROM cars WHERE id=?")
+	statement, err := c.prepare(ctxTracing, tx, "SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE id=? AND deleted_at IS NULL")
 	if err != nil {
 		span.LogFields(log.String("error", err.Error()))
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 	}
+	defer statement.Close()
 
 	// query
 	row := statement.QueryRowContext(ctxTracing, id)
@@ -121,17 +284,17 @@ func (c *CarRepository) GetDetail(ctx context.Context, tx *sql.Tx, id int) (*ent
 			return nil, customError.NewNotFoundError(row.Err().Error())
 		}
 
-		return nil, customError.NewInternalServerError(row.Err().Error())
+		return nil, customError.NewInternalServerError(row.Err().Error(), customError.WithCause(row.Err()))
 	}
 
 	var response entity.Car
-	if err := row.Scan(&response.Id, &response.Name, &response.Price, &response.ReleaseDate); err != nil {
+	if err := row.Scan(&response.Id, &response.Name, &response.Price, &response.ReleaseDate, &response.DeletedAt, &response.Version, &response.UpdatedAt); err != nil {
 		span.LogFields(log.String("error", err.Error()))
 		if err == sql.ErrNoRows {
 			return nil, customError.NewNotFoundError(err.Error())
 		}
 
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
 	}
 
 	// success get data
@@ -139,3 +302,264 @@ func (c *CarRepository) GetDetail(ctx context.Context, tx *sql.Tx, id int) (*ent
 	span.LogFields(log.String("response", string(resJson)))
 	return &response, nil
 }
+
+// method implementasi update seluruh field by id, hanya jika version masih sama
+func (c *CarRepository) Update(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error) {
+	// start span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Update")
+	defer span.Finish()
+
+	reqJson, _ := json.Marshal(&input)
+	span.LogFields(log.String("request", string(reqJson)))
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx,
+		"UPDATE cars SET name=?, price=?, release_date=?, version=version+1 WHERE id=? AND version=? AND deleted_at IS NULL")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctxTracing, input.Name, input.Price, input.ReleaseDate.Time, input.Id, input.Version)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, writeError(err)
+	}
+
+	if err := c.checkVersionMatched(ctxTracing, tx, result, input.Id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// success update, return stored row
+	return c.GetDetail(ctxTracing, tx, input.Id)
+}
+
+// method implementasi update sebagian field by id, hanya jika version masih sama
+func (c *CarRepository) Patch(ctx context.Context, tx *sql.Tx, id int, version int, input *entity.CarPatch) error {
+	// start span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Patch")
+	defer span.Finish()
+
+	reqJson, _ := json.Marshal(&input)
+	span.LogFields(log.Int("id", id), log.Int("version", version), log.String("request", string(reqJson)))
+
+	// only the supplied columns are written
+	var columns []string
+	var args []any
+	if input.Name != nil {
+		columns = append(columns, "name=?")
+		args = append(args, *input.Name)
+	}
+	if input.Price != nil {
+		columns = append(columns, "price=?")
+		args = append(args, *input.Price)
+	}
+	if input.ReleaseDate != nil {
+		columns = append(columns, "release_date=?")
+		args = append(args, input.ReleaseDate.Time)
+	}
+
+	if len(columns) == 0 {
+		return customError.NewBadRequestError("no field to update")
+	}
+
+	// prepare query
+	columns = append(columns, "version=version+1")
+	query := fmt.Sprintf("UPDATE cars SET %v WHERE id=? AND version=? AND deleted_at IS NULL", strings.Join(columns, ", "))
+	statement, err := c.prepare(ctxTracing, tx, query)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	args = append(args, id, version)
+	result, err := statement.ExecContext(ctxTracing, args...)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return writeError(err)
+	}
+
+	if err := c.checkVersionMatched(ctxTracing, tx, result, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return err
+	}
+
+	// success patch
+	return nil
+}
+
+// method implementasi soft delete by id, data masih bisa di restore
+func (c *CarRepository) Delete(ctx context.Context, tx *sql.Tx, id int, version int) error {
+	// start span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Delete")
+	defer span.Finish()
+
+	span.LogFields(log.Int("id", id), log.Int("version", version))
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx,
+		"UPDATE cars SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE id=? AND version=? AND deleted_at IS NULL")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctxTracing, id, version)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	if err := c.checkVersionMatched(ctxTracing, tx, result, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return err
+	}
+
+	// success delete
+	return nil
+}
+
+// when no row changed, find out if the data is gone or the version is outdated
+func (c *CarRepository) checkVersionMatched(ctx context.Context, tx *sql.Tx, result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var version int
+	err = c.queryRow(ctx, tx, "SELECT version FROM cars WHERE id=? AND deleted_at IS NULL", id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return customError.NewNotFoundError("record not found")
+	}
+	if err != nil {
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	return customError.NewPreconditionFailedError(fmt.Sprintf("version not match, current version is %v", version))
+}
+
+// method implementasi restore data yang sudah di soft delete
+func (c *CarRepository) Restore(ctx context.Context, tx *sql.Tx, id int) error {
+	// start span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Restore")
+	defer span.Finish()
+
+	span.LogFields(log.Int("id", id))
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx, "UPDATE cars SET deleted_at=NULL, version=version+1 WHERE id=? AND deleted_at IS NOT NULL")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctxTracing, id)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return writeError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	// if not found in trash
+	if rowsAffected == 0 {
+		return customError.NewNotFoundError("record not found")
+	}
+
+	// success restore
+	return nil
+}
+
+// method implementasi hapus permanen data yang di soft delete sebelum waktu before
+func (c *CarRepository) Purge(ctx context.Context, tx *sql.Tx, before time.Time) (int, error) {
+	// start span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository Purge")
+	defer span.Finish()
+
+	span.LogFields(log.String("before", before.Format(time.RFC3339)))
+
+	// prepare query
+	statement, err := c.prepare(ctxTracing, tx, "DELETE FROM cars WHERE deleted_at IS NOT NULL AND deleted_at < ?")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctxTracing, before)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	// success purge
+	span.LogFields(log.Int64("total", rowsAffected))
+	return int(rowsAffected), nil
+}
+
+// method implementasi cek mobil lain yang belum dihapus dengan nama dan tanggal rilis yang sama.
+// release_date disimpan sebagai timestamp, jadi dibandingkan dengan kolom release_day
+func (c *CarRepository) IsDuplicate(ctx context.Context, tx *sql.Tx, name string, releaseDate time.Time, excludeId int) (bool, error) {
+	// start span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Repository IsDuplicate")
+	defer span.Finish()
+
+	span.LogFields(log.String("name", name), log.String("release_date", helper.DateToString(releaseDate)), log.Int("exclude_id", excludeId))
+
+	// prepare query, lock the matching entry of uq_cars_name_release_day_alive until the write commit
+	statement, err := c.prepare(ctxTracing, tx, "SELECT COUNT(id) FROM cars WHERE name = ? AND release_day = ? AND alive = 1 AND id <> ? FOR UPDATE")
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return false, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer statement.Close()
+
+	var total int
+	if err := statement.QueryRowContext(ctxTracing, name, helper.DateToString(releaseDate), excludeId).Scan(&total); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return false, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	duplicate := total > 0
+	span.LogFields(log.Bool("duplicate", duplicate))
+	return duplicate, nil
+}
+
+// Invalidate do nothing, database is always up to date
+func (c *CarRepository) Invalidate(ctx context.Context, ids ...int) {}
+
+// mysql error number of duplicate entry on unique index
+const errDuplicateEntry = 1062
+
+// writeError map error of insert or update, duplicate entry mean other car has the same name and release date
+func writeError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		message := "car with the same name and release date already exist"
+		return customError.NewConflictError(message, customError.WithCode("car_duplicate"), customError.WithCause(err), customError.WithDetails(
+			customError.Detail{Field: "name", Rule: "unique", Message: message},
+			customError.Detail{Field: "release_date", Rule: "unique", Message: message},
+		))
+	}
+
+	return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+}
@@ -8,5 +8,14 @@ import (
 func GenerateCarRouter(app fiber.Router, handler *handler.CarHandler) {
 	app.Post("/car", handler.InsertData)
 	app.Get("/cars", handler.GetAll)
+	app.Post("/cars/bulk", handler.InsertBulk)
+	app.Get("/cars/export", handler.Export)
+	app.Post("/cars/import", handler.Import)
+	app.Get("/cars/trash", handler.GetAllDeleted)
+	app.Delete("/cars/trash", handler.Purge)
 	app.Get("/car/:id", handler.GetDetail)
+	app.Put("/car/:id", handler.UpdateData)
+	app.Patch("/car/:id", handler.PatchData)
+	app.Delete("/car/:id", handler.DeleteData)
+	app.Post("/car/:id/restore", handler.Restore)
 }
@@ -0,0 +1,11 @@
+package router
+
+import (
+	"cobaApp/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func GenerateHealthRouter(app fiber.Router, handler *handler.HealthHandler) {
+	app.Get("/healthz", handler.Liveness)
+	app.Get("/readyz", handler.Readiness)
+}
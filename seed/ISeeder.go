@@ -0,0 +1,13 @@
+package seed
+
+import (
+	"context"
+	"io"
+)
+
+type ISeeder interface {
+	Load(ctx context.Context, r io.Reader, format string) (*Result, error)
+	LoadFile(ctx context.Context, path string) (*Result, error)
+	LoadDefault(ctx context.Context) (*Result, error)
+	Generate(ctx context.Context, count int) (*Result, error)
+}
@@ -0,0 +1,88 @@
+package seed
+
+import (
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type carModel struct {
+	brand    string
+	model    string
+	minPrice float64
+	maxPrice float64
+}
+
+// price range in rupiah, roughly the indonesian market
+var carModels = []carModel{
+	{"Toyota", "Avanza", 230_000_000, 300_000_000},
+	{"Toyota", "Innova Zenix", 420_000_000, 620_000_000},
+	{"Toyota", "Fortuner", 560_000_000, 720_000_000},
+	{"Toyota", "Yaris Cross", 350_000_000, 440_000_000},
+	{"Daihatsu", "Xenia", 220_000_000, 280_000_000},
+	{"Daihatsu", "Sigra", 135_000_000, 185_000_000},
+	{"Honda", "Brio", 165_000_000, 240_000_000},
+	{"Honda", "HR-V", 380_000_000, 560_000_000},
+	{"Honda", "CR-V", 550_000_000, 800_000_000},
+	{"Mitsubishi", "Xpander", 260_000_000, 340_000_000},
+	{"Mitsubishi", "Pajero Sport", 550_000_000, 770_000_000},
+	{"Suzuki", "Ertiga", 230_000_000, 300_000_000},
+	{"Hyundai", "Creta", 300_000_000, 500_000_000},
+	{"Hyundai", "Ioniq 5", 720_000_000, 860_000_000},
+	{"Wuling", "Air ev", 190_000_000, 300_000_000},
+}
+
+var carTrims = []string{"E", "G", "S", "V", "Q", "GX", "Sport", "Ultimate", "Limited", "Signature"}
+
+var carTransmissions = []string{"MT", "AT", "CVT"}
+
+// Generator create synthetic cars, use a fixed seed to get the same cars every run
+type Generator struct {
+	rand *rand.Rand
+	now  time.Time
+
+	// name and release date already generated, cars table allow only one car per pair
+	used map[string]struct{}
+}
+
+// function provider
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		rand: rand.New(rand.NewSource(seed)),
+		now:  time.Now(),
+		used: map[string]struct{}{},
+	}
+}
+
+// Car return a car released in the last ten years, price rounded to hundred thousand.
+// name and release date never repeat, the pair is generated again on collision
+func (g *Generator) Car() dto.InsertCarRequest {
+	for {
+		car := g.car()
+		key := car.Name + "|" + car.ReleaseDate
+		if _, ok := g.used[key]; !ok {
+			g.used[key] = struct{}{}
+			return car
+		}
+	}
+}
+
+func (g *Generator) car() dto.InsertCarRequest {
+	model := carModels[g.rand.Intn(len(carModels))]
+	trim := carTrims[g.rand.Intn(len(carTrims))]
+	transmission := carTransmissions[g.rand.Intn(len(carTransmissions))]
+
+	price := model.minPrice + g.rand.Float64()*(model.maxPrice-model.minPrice)
+	price = math.Round(price/100_000) * 100_000
+
+	releaseDate := g.now.AddDate(0, 0, -g.rand.Intn(10*365))
+
+	return dto.InsertCarRequest{
+		Name:        fmt.Sprintf("%v %v %v %v", model.brand, model.model, trim, transmission),
+		Price:       price,
+		ReleaseDate: helper.DateToString(releaseDate),
+	}
+}
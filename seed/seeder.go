@@ -0,0 +1,167 @@
+package seed
+
+import (
+	"cobaApp/model/dto"
+	"cobaApp/service"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// supported fixture format
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+//go:embed fixtures/cars.yaml
+var fixtures embed.FS
+
+const defaultFixture = "fixtures/cars.yaml"
+
+// Fixture is the content of fixture file, e.g.
+//
+//	cars:
+//	  - name: Toyota Avanza
+//	    price: 268000000
+//	    release_date: "2021-11-11"
+type Fixture struct {
+	Cars []FixtureCar `json:"cars" yaml:"cars"`
+}
+
+type FixtureCar struct {
+	Name        string  `json:"name" yaml:"name"`
+	Price       float64 `json:"price" yaml:"price"`
+	ReleaseDate string  `json:"release_date" yaml:"release_date"`
+}
+
+type Result struct {
+	Total   int                     `json:"total"`
+	Success int                     `json:"success"`
+	Failed  int                     `json:"failed"`
+	Cars    []dto.InsertCarResponse `json:"cars,omitempty"`
+	Errors  []string                `json:"errors,omitempty"`
+}
+
+// Seeder insert cars through ICarService, so every car pass the same validation as the api
+type Seeder struct {
+	CarService service.ICarService
+	Generator  *Generator
+	Log        *logrus.Logger
+}
+
+// function provider
+func NewSeeder(carService service.ICarService, generator *Generator, log *logrus.Logger) ISeeder {
+	return &Seeder{
+		CarService: carService,
+		Generator:  generator,
+		Log:        log,
+	}
+}
+
+// Format resolve fixture format from file name
+func Format(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	case ".json":
+		return FormatJSON, true
+	}
+
+	return "", false
+}
+
+// Load insert every car of the fixture, a failed car is reported and the rest is still inserted
+func (s *Seeder) Load(ctx context.Context, r io.Reader, format string) (*Result, error) {
+	var fixture Fixture
+
+	switch format {
+	case FormatYAML:
+		if err := yaml.NewDecoder(r).Decode(&fixture); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("cant parse fixture : %w", err)
+		}
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&fixture); err != nil {
+			return nil, fmt.Errorf("cant parse fixture : %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("fixture format must be %v or %v", FormatYAML, FormatJSON)
+	}
+
+	requests := make([]dto.InsertCarRequest, 0, len(fixture.Cars))
+	for _, car := range fixture.Cars {
+		requests = append(requests, dto.InsertCarRequest{
+			Name:        car.Name,
+			Price:       car.Price,
+			ReleaseDate: car.ReleaseDate,
+		})
+	}
+
+	return s.insert(ctx, requests), nil
+}
+
+func (s *Seeder) LoadFile(ctx context.Context, path string) (*Result, error) {
+	format, ok := Format(path)
+	if !ok {
+		return nil, fmt.Errorf("fixture %v must be .yaml, .yml or .json", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return s.Load(ctx, file, format)
+}
+
+// LoadDefault insert the sample catalogue shipped inside the binary
+func (s *Seeder) LoadDefault(ctx context.Context) (*Result, error) {
+	file, err := fixtures.Open(defaultFixture)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return s.Load(ctx, file, FormatYAML)
+}
+
+// Generate insert count synthetic cars
+func (s *Seeder) Generate(ctx context.Context, count int) (*Result, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("count must be greater than 0, got %v", count)
+	}
+
+	requests := make([]dto.InsertCarRequest, 0, count)
+	for i := 0; i < count; i++ {
+		requests = append(requests, s.Generator.Car())
+	}
+
+	return s.insert(ctx, requests), nil
+}
+
+func (s *Seeder) insert(ctx context.Context, requests []dto.InsertCarRequest) *Result {
+	result := &Result{Total: len(requests)}
+
+	for i := range requests {
+		car, err := s.CarService.Insert(ctx, &requests[i])
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("car %v [%v] : %v", i, requests[i].Name, err))
+			continue
+		}
+
+		result.Success++
+		result.Cars = append(result.Cars, *car)
+	}
+
+	s.Log.Infof("seed %v car, %v success, %v failed", result.Total, result.Success, result.Failed)
+	return result
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"cobaApp/config"
+	"cobaApp/database"
+	"cobaApp/helper"
+	"cobaApp/repository"
+	"cobaApp/seed"
+	"cobaApp/service"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strconv"
+	"time"
+)
+
+// runSeed handle `cobaApp seed ...`, without args the sample catalogue is loaded
+func runSeed(cfg config.IConfig, log *logrus.Logger, args []string) int {
+	count, randSeed := 0, time.Now().UnixNano()
+	if len(args) > 0 && args[0] == "generate" {
+		if len(args) < 2 || len(args) > 3 {
+			return usageError("seed")
+		}
+
+		var err error
+		if count, err = strconv.Atoi(args[1]); err != nil || count < 1 {
+			fmt.Fprintf(os.Stderr, "invalid count %q\n", args[1])
+			return 2
+		}
+		if len(args) == 3 {
+			if randSeed, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid seed %q\n", args[2])
+				return 2
+			}
+		}
+	}
+
+	ctx := context.Background()
+	db, err := database.ConnectDatabase(ctx, cfg, log)
+	if err != nil {
+		log.Errorf("cant connect database : %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	carService := service.NewCarService(db, helper.NewValidator(), repository.NewCarRepository(db), cfg)
+	seeder := seed.NewSeeder(carService, seed.NewGenerator(randSeed), log)
+
+	var results []*seed.Result
+	switch {
+	case count > 0:
+		result, err := seeder.Generate(ctx, count)
+		if err != nil {
+			log.Errorf("cant generate cars : %v", err)
+			return 1
+		}
+		results = append(results, result)
+	case len(args) == 0:
+		result, err := seeder.LoadDefault(ctx)
+		if err != nil {
+			log.Errorf("cant load sample fixture : %v", err)
+			return 1
+		}
+		results = append(results, result)
+	default:
+		for _, path := range args {
+			result, err := seeder.LoadFile(ctx, path)
+			if err != nil {
+				log.Errorf("cant load fixture %v : %v", path, err)
+				return 1
+			}
+			results = append(results, result)
+		}
+	}
+
+	exitCode := 0
+	for _, result := range results {
+		// inserted cars is not printed, it can be thousands
+		result.Cars = nil
+		content, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(content))
+
+		if result.Failed > 0 {
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
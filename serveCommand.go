@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cobaApp/config"
+	"cobaApp/database"
+	"cobaApp/server"
+	tracing "cobaApp/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// runServe start the http server until it fail or the process get stop signal
+func runServe(cfg config.IConfig, log *logrus.Logger, args []string) int {
+	if len(args) > 0 {
+		return usageError("serve")
+	}
+
+	fmt.Println("haloo semua")
+	fmt.Println(cfg.GetConfig().Database.Name)
+
+	// define tracing
+	tracer, closer, reporterHealth := tracing.GenerateTracing(cfg, log, "cobaApp")
+
+	opentracing.SetGlobalTracer(tracer)
+
+	// connect to database
+	db, err := database.ConnectDatabase(context.Background(), cfg, log)
+	if err != nil {
+		log.Errorf("cant connect database : %v", err)
+		closer.Close()
+		return 1
+	}
+
+	appServer, err := server.NewAppServer(db, cfg, log, reporterHealth)
+	if err != nil {
+		log.Errorf("cant create app server : %v", err)
+		closer.Close()
+		db.Close()
+		return 1
+	}
+
+	// reload runtime-safe config on file change or SIGHUP
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+
+	err = cfg.Watch(watchCtx, func(err error) {
+		var restartErr *config.RestartRequiredError
+		if errors.As(err, &restartErr) {
+			log.Warnf("success reload config, ignored until restart : %v", strings.Join(restartErr.Keys, ", "))
+			return
+		}
+		if err != nil {
+			log.Errorf("cant reload config : %v", err)
+			return
+		}
+
+		log.Info("success reload config")
+	})
+	if err != nil {
+		log.Warnf("cant watch config : %v", err)
+	}
+
+	// run server until it fail or the process get stop signal
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- appServer.RunServer()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		log.Errorf("cant start app : %v", err)
+		exitCode = 1
+	case sig := <-quit:
+		log.Infof("receive signal [%v], shutting down", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GetConfig().App.ShutdownTimeout)
+		if err := appServer.Shutdown(ctx); err != nil {
+			log.Errorf("cant drain in-flight request : %v", err)
+			exitCode = 1
+		} else {
+			log.Info("success stop server")
+		}
+		cancel()
+	}
+
+	stopWatch()
+
+	// close in order, tracer flush buffered span then database
+	if err := closer.Close(); err != nil {
+		log.Errorf("cant close tracer : %v", err)
+	} else {
+		log.Info("success close tracer")
+	}
+
+	if err := db.Close(); err != nil {
+		log.Errorf("cant close database : %v", err)
+	} else {
+		log.Info("success close database")
+	}
+
+	return exitCode
+}
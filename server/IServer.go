@@ -1,5 +1,8 @@
 package server
 
+import "context"
+
 type IServer interface {
 	RunServer() error
+	Shutdown(ctx context.Context) error
 }
@@ -1,57 +1,98 @@
 package server
 
 import (
+	"cobaApp/cache"
 	"cobaApp/config"
 	"cobaApp/handler"
+	"cobaApp/helper"
+	"cobaApp/middleware"
 	"cobaApp/repository"
 	"cobaApp/router"
 	"cobaApp/service"
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/ansrivas/fiberprometheus/v2"
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 type AppServer struct {
-	Router *fiber.App
-	Config config.IConfig
+	Router        *fiber.App
+	Config        config.IConfig
+	HealthHandler *handler.HealthHandler
 }
 
-func NewAppServer(db *sql.DB, config config.IConfig, log *logrus.Logger) IServer {
+func NewAppServer(db *sql.DB, config config.IConfig, log *logrus.Logger, tracer handler.HealthChecker) (IServer, error) {
 	// register validate
-	validate := validator.New()
+	validate := helper.NewValidator()
+	translator, err := helper.NewValidationTranslator(validate)
+	if err != nil {
+		return nil, err
+	}
 
 	// register repository
 	carRepo := repository.NewCarRepository(db)
+	if cacheConfig := config.GetConfig().Cache; cacheConfig != nil && cacheConfig.Enabled {
+		carRepo = repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(cacheConfig.Capacity), config)
+	}
 
 	// register service
-	carService := service.NewCarService(db, validate, carRepo)
+	carService := service.NewCarService(db, validate, carRepo, config)
 
 	// register handler
-	carHandler := handler.NewCarHandler(carService, log)
+	carHandler := handler.NewCarHandler(carService, translator, log)
+	healthHandler := handler.NewHealthHandler(db, tracer, config)
 
 	app := fiber.New(fiber.Config{
-		Prefork: false,
+		Prefork:      false,
+		ErrorHandler: handler.NewErrorHandler(config, translator, log),
+		JSONDecoder:  helper.StrictJSONUnmarshal,
 	})
 
+	// panic in handler become internal server error instead of killing the process
+	app.Use(recover.New())
+
 	prometheus := fiberprometheus.New("cobaApp-metrics")
 	prometheus.RegisterAt(app, "/metrics")
 	app.Use(prometheus.Middleware)
 
+	// probe router, outside v1 so it is not rate limited
+	router.GenerateHealthRouter(app, healthHandler)
+
 	v1 := app.Group("/v1")
+	v1.Use(middleware.RateLimitMiddleware(config))
+	v1.Use(middleware.CacheControlMiddleware(config))
 
 	// car router
 	router.GenerateCarRouter(v1, carHandler)
 
 	return &AppServer{
-		Router: app,
-		Config: config,
-	}
+		Router:        app,
+		Config:        config,
+		HealthHandler: healthHandler,
+	}, nil
 }
 
 func (a *AppServer) RunServer() error {
 	err := a.Router.Listen(fmt.Sprintf(":%v", a.Config.GetConfig().App.Port))
 	return err
 }
+
+// Shutdown make readiness fail and keep serving for app.drain_delay, so the load balancer see it
+// and stop routing new traffic. then stop accepting connection and wait in-flight request until ctx is done
+func (a *AppServer) Shutdown(ctx context.Context) error {
+	a.HealthHandler.MarkShuttingDown()
+
+	drain := time.NewTimer(a.Config.GetConfig().App.DrainDelay)
+	defer drain.Stop()
+
+	select {
+	case <-drain.C:
+	case <-ctx.Done():
+	}
+
+	return a.Router.ShutdownWithContext(ctx)
+}
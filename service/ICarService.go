@@ -3,10 +3,22 @@ package service
 import (
 	"cobaApp/model/dto"
 	"context"
+	"io"
+	"time"
 )
 
 type ICarService interface {
 	Insert(ctx context.Context, request *dto.InsertCarRequest) (*dto.InsertCarResponse, error)
-	GetAll(ctx context.Context) ([]dto.InsertCarResponse, error)
+	InsertBulk(ctx context.Context, requests []dto.InsertCarRequest, mode string) (*dto.BulkInsertCarResponse, error)
+	Import(ctx context.Context, r io.Reader, format string, mode string) (*dto.BulkInsertCarResponse, error)
+	Export(ctx context.Context, w io.Writer, format string) error
+	GetAll(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error)
 	GetDetail(ctx context.Context, id int) (*dto.InsertCarResponse, error)
+	LastModified(ctx context.Context) (time.Time, error)
+	Update(ctx context.Context, id int, version int, request *dto.UpdateCarRequest) (*dto.InsertCarResponse, error)
+	Patch(ctx context.Context, id int, version int, request *dto.PatchCarRequest) (*dto.InsertCarResponse, error)
+	Delete(ctx context.Context, id int, version int) error
+	GetAllDeleted(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error)
+	Restore(ctx context.Context, id int) (*dto.InsertCarResponse, error)
+	Purge(ctx context.Context) (int, error)
 }
@@ -1,32 +1,58 @@
 package service
 
 import (
+	"cobaApp/config"
 	"cobaApp/customError"
 	"cobaApp/helper"
 	"cobaApp/model/dto"
 	"cobaApp/model/entity"
 	"cobaApp/repository"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
+	"io"
 	"time"
 )
 
+// default jumlah data per page
+const defaultLimit = 10
+
+// mode bulk insert
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+// maksimal jumlah data sekali bulk insert
+const maxBulkSize = 1000
+
+// jumlah baris file import yang diproses sekaligus
+const importChunkSize = 500
+
+// default lama data disimpan di trash sebelum bisa dihapus permanen
+const defaultTrashRetention = 30 * 24 * time.Hour
+
 type CarService struct {
 	DB            *sql.DB
 	Validate      *validator.Validate
 	CarRepository repository.ICarRepository
+	Config        config.IConfig
 }
 
 // function provider
-func NewCarService(db *sql.DB, validate *validator.Validate, carRepo repository.ICarRepository) ICarService {
+func NewCarService(db *sql.DB, validate *validator.Validate, carRepo repository.ICarRepository, cfg config.IConfig) ICarService {
 	return &CarService{
 		DB:            db,
 		Validate:      validate,
 		CarRepository: carRepo,
+		Config:        cfg,
 	}
 }
 
@@ -35,6 +61,8 @@ func (c *CarService) Insert(ctx context.Context, request *dto.InsertCarRequest)
 	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Insert")
 	defer span.Finish()
 
+	request.Name = helper.NormalizeName(request.Name)
+
 	reqJson, _ := json.Marshal(&request)
 	span.LogFields(log.String("request", string(reqJson)))
 
@@ -69,61 +97,373 @@ func (c *CarService) Insert(ctx context.Context, request *dto.InsertCarRequest)
 	defer tx.Rollback()
 
 	// run query in repository
-	result, err := c.CarRepository.Insert(ctxTracing, tx, &input)
+	result, err := c.insertUnique(ctxTracing, tx, &input)
 	if err != nil {
 		return nil, err
 	}
 
 	// success insert
-	tx.Commit()
+	if err := c.commit(ctxTracing, tx, result.Id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
 
 	// create respone
-	response := dto.InsertCarResponse{
-		Id:    result.Id,
-		Name:  result.Name,
-		Price: result.Price,
+	response := toCarResponse(result)
+
+	resJson, _ := json.Marshal(&response)
+	span.LogFields(log.String("response", string(resJson)))
+
+	// return response
+	return &response, nil
+}
+
+func (c *CarService) InsertBulk(ctx context.Context, requests []dto.InsertCarRequest, mode string) (*dto.BulkInsertCarResponse, error) {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service InsertBulk")
+	defer span.Finish()
+
+	span.LogFields(log.String("mode", mode), log.Int("total", len(requests)))
+
+	if mode == "" {
+		mode = BulkModeAtomic
+	}
+	if mode != BulkModeAtomic && mode != BulkModeBestEffort {
+		return nil, customError.NewBadRequestError("mode must be atomic or best_effort")
+	}
+	if len(requests) == 0 {
+		return nil, customError.NewBadRequestError("data cant be empty")
+	}
+	if len(requests) > maxBulkSize {
+		return nil, customError.NewBadRequestError(fmt.Sprintf("maximum %v data per request", maxBulkSize))
 	}
 
-	if result.ReleaseDate.Valid {
-		response.ReleaseDate = result.ReleaseDate.Time.Format("2006-01-02")
-	} else {
-		response.ReleaseDate = ""
+	response := dto.BulkInsertCarResponse{
+		Mode:    mode,
+		Total:   len(requests),
+		Results: make([]dto.BulkInsertCarResult, len(requests)),
 	}
 
+	// validate every row first
+	for i := range requests {
+		response.Results[i].Index = i
+		c.validateRow(ctxTracing, &requests[i], &response.Results[i])
+	}
+
+	// all or nothing, dont touch database if there is invalid row
+	if mode == BulkModeAtomic && countFailed(response.Results) > 0 {
+		response.Failed = countFailed(response.Results)
+		return &response, customError.NewBadRequestError("bulk insert canceled, some data is invalid")
+	}
+
+	if mode == BulkModeBestEffort {
+		// every row has its own transaction, so a failed commit only lose its own row
+		for i := range requests {
+			if response.Results[i].Error != "" {
+				continue
+			}
+
+			car, err := c.insertRow(ctxTracing, &requests[i])
+			if err != nil {
+				span.LogFields(log.Int("index", i), log.String("error", err.Error()))
+				response.Results[i].Error = err.Error()
+				continue
+			}
+
+			data := toCarResponse(car)
+			response.Results[i].Data = &data
+		}
+	} else if err := c.insertAtomic(ctxTracing, requests, &response); err != nil {
+		return &response, err
+	}
+
+	for i := range response.Results {
+		response.Results[i].Success = response.Results[i].Error == ""
+	}
+	response.Failed = countFailed(response.Results)
+	response.Success = response.Total - response.Failed
+
 	resJson, _ := json.Marshal(&response)
 	span.LogFields(log.String("response", string(resJson)))
 
-	// return response
 	return &response, nil
 }
 
-func (c *CarService) GetAll(ctx context.Context) ([]dto.InsertCarResponse, error) {
+func (c *CarService) Import(ctx context.Context, r io.Reader, format string, mode string) (*dto.BulkInsertCarResponse, error) {
 	// start tracing
-	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service GetAll")
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Import")
 	defer span.Finish()
 
-	// create transaction
-	tx, _ := c.DB.Begin()
+	span.LogFields(log.String("format", format), log.String("mode", mode))
+
+	if mode == "" {
+		mode = BulkModeAtomic
+	}
+	if mode != BulkModeAtomic && mode != BulkModeBestEffort {
+		return nil, customError.NewBadRequestError("mode must be atomic or best_effort")
+	}
+
+	reader, err := helper.NewCarFileReader(r, format)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, customError.NewBadRequestError(err.Error())
+	}
+
+	state := &carImport{response: dto.BulkInsertCarResponse{Mode: mode, Results: []dto.BulkInsertCarResult{}}}
+	defer func() {
+		if state.tx != nil {
+			state.tx.Rollback()
+		}
+	}()
+
+	// file is streamed chunk by chunk, there is no limit of rows like bulk insert
+	chunk := make([]helper.CarFileRow, 0, importChunkSize)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			span.LogFields(log.String("error", err.Error()))
+			return nil, customError.NewBadRequestError(err.Error())
+		}
+
+		if chunk = append(chunk, *row); len(chunk) == importChunkSize {
+			c.importChunk(ctxTracing, state, chunk)
+			chunk = chunk[:0]
+		}
+	}
+	if len(chunk) > 0 {
+		c.importChunk(ctxTracing, state, chunk)
+	}
+
+	response := &state.response
+	if response.Total == 0 {
+		return nil, customError.NewBadRequestError("file has no data")
+	}
+
+	if mode == BulkModeAtomic {
+		if response.Failed > 0 {
+			// rollback every row inserted before
+			for i := range response.Results {
+				response.Results[i].Data = nil
+			}
+			if state.insertErr == nil {
+				return response, customError.NewBadRequestError("import canceled, some line is invalid")
+			}
+			if errors.Is(state.insertErr, customError.ErrConflict) {
+				return response, customError.NewConflictError("import rolled back : "+state.insertErr.Error(), customError.WithCause(state.insertErr))
+			}
+			return response, customError.NewInternalServerError("import rolled back : "+state.insertErr.Error(), customError.WithCause(state.insertErr))
+		}
+
+		if state.tx != nil {
+			if err := state.tx.Commit(); err != nil {
+				span.LogFields(log.String("error", err.Error()))
+				for i := range response.Results {
+					response.Results[i].Data = nil
+					response.Results[i].Error = "import rolled back : " + err.Error()
+				}
+				response.Failed = response.Total
+				return response, customError.NewInternalServerError("import rolled back : "+err.Error(), customError.WithCause(err))
+			}
+			state.tx = nil
+			c.CarRepository.Invalidate(ctxTracing)
+		}
+	}
+
+	for i := range response.Results {
+		response.Results[i].Success = response.Results[i].Error == ""
+	}
+	response.Success = response.Total - response.Failed
+
+	span.LogFields(log.Int("total", response.Total), log.Int("failed", response.Failed))
+	return response, nil
+}
+
+// carImport is the state of one import, atomic mode share one transaction opened on the first row to insert
+type carImport struct {
+	tx       *sql.Tx
+	response dto.BulkInsertCarResponse
+
+	// insertErr is the insert failure that roll back atomic import
+	insertErr error
+}
+
+// importChunk validate the whole chunk first then insert its valid rows. atomic mode stop inserting
+// after the first failed row but keep validating, so the report still list every invalid line
+func (c *CarService) importChunk(ctx context.Context, state *carImport, rows []helper.CarFileRow) {
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service ImportChunk")
+	defer span.Finish()
+
+	span.LogFields(log.Int("first_line", rows[0].Line), log.Int("size", len(rows)))
+
+	response := &state.response
+	first := len(response.Results)
+	for i := range rows {
+		result := dto.BulkInsertCarResult{Index: response.Total, Line: rows[i].Line}
+		response.Total++
+
+		if rows[i].Err != nil {
+			result.Error = rows[i].Err.Error()
+		} else {
+			c.validateRow(ctxTracing, &rows[i].Request, &result)
+		}
+		if result.Error != "" {
+			response.Failed++
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	atomic := response.Mode == BulkModeAtomic
+	for i := range rows {
+		result := &response.Results[first+i]
+		if result.Error != "" || (atomic && response.Failed > 0) {
+			continue
+		}
+
+		car, err := c.importRow(ctxTracing, state, &rows[i].Request)
+		if err != nil {
+			span.LogFields(log.Int("line", rows[i].Line), log.String("error", err.Error()))
+			result.Error = err.Error()
+			response.Failed++
+			if atomic {
+				state.insertErr = err
+			}
+			continue
+		}
+
+		data := toCarResponse(car)
+		result.Data = &data
+	}
+}
+
+// importRow insert into the shared transaction in atomic mode, otherwise in its own transaction
+func (c *CarService) importRow(ctx context.Context, state *carImport, request *dto.InsertCarRequest) (*entity.Car, error) {
+	if state.response.Mode != BulkModeAtomic {
+		return c.insertRow(ctx, request)
+	}
+
+	if state.tx == nil {
+		tx, err := c.DB.Begin()
+		if err != nil {
+			return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+		}
+		state.tx = tx
+	}
+
+	input := newCarEntity(request)
+	return c.insertUnique(ctx, state.tx, &input)
+}
+
+func (c *CarService) Export(ctx context.Context, w io.Writer, format string) error {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Export")
+	defer span.Finish()
+
+	span.LogFields(log.String("format", format))
+
+	// create transaction, so export read one consistent snapshot
+	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
 	defer tx.Rollback()
 
-	// run query in repository
-	cars, err := c.CarRepository.GetAll(ctxTracing, tx)
+	writer := helper.NewCarFileWriter(w, format)
+	err = c.CarRepository.Iterate(ctxTracing, tx, func(car *entity.Car) error {
+		response := toCarResponse(car)
+		return writer.Write(&response)
+	})
 	if err != nil {
-		return nil, err
+		span.LogFields(log.String("error", err.Error()))
+		return err
 	}
 
-	// commit transaction
 	tx.Commit()
+	return writer.Flush()
+}
+
+func (c *CarService) GetAll(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error) {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service GetAll")
+	defer span.Finish()
+
+	if err := c.Validate.StructCtx(ctxTracing, *query); err != nil {
+		// return error validator
+		return nil, nil, err
+	}
+
+	// set default value query spec
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Sort == "" {
+		query.Sort = "id"
+	}
+	if query.Order == "" {
+		query.Order = "asc"
+	}
+
+	// cursor mode, sort and order follow the cursor
+	cursorMode := query.Pagination == "cursor" || query.Cursor != ""
+	if query.Cursor != "" {
+		var cursor dto.CarCursor
+		if err := helper.DecodeCursor(c.cursorSecret(), query.Cursor, &cursor); err != nil {
+			return nil, nil, customError.NewBadRequestError(err.Error())
+		}
+		if cursor.Filter != carFilterKey(query) {
+			return nil, nil, customError.NewBadRequestError("cursor was created with different filter")
+		}
+
+		query.Sort, query.Order, query.After = cursor.Sort, cursor.Order, &cursor
+	}
+
+	reqJson, _ := json.Marshal(&query)
+	span.LogFields(log.String("request", string(reqJson)))
+
+	if cursorMode {
+		return c.getAllCursor(ctxTracing, query)
+	}
+
+	// read without transaction, so it can be served from cache
+	// count total data for page meta
+	total, err := c.CarRepository.Count(ctxTracing, nil, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// run query in repository
+	cars, err := c.CarRepository.GetAll(ctxTracing, nil, query)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// convert to response
 	var response = []dto.InsertCarResponse{}
-	for _, data := range cars {
-		response = append(response, dto.InsertCarResponse{
-			Id:          data.Id,
-			Name:        data.Name,
-			Price:       data.Price,
-			ReleaseDate: helper.DateToString(data.ReleaseDate.Time),
-		})
+	for i := range cars {
+		response = append(response, toCarResponse(&cars[i]))
+	}
+
+	// create page meta
+	meta := dto.PageMeta{
+		Page:      query.Page,
+		Limit:     query.Limit,
+		TotalData: total,
+		TotalPage: (total + query.Limit - 1) / query.Limit,
+	}
+	if meta.Page < meta.TotalPage {
+		nextPage := meta.Page + 1
+		meta.NextPage = &nextPage
+	}
+	if meta.Page > 1 {
+		prevPage := meta.Page - 1
+		meta.PrevPage = &prevPage
 	}
 
 	// log to tracing
@@ -131,7 +471,102 @@ func (c *CarService) GetAll(ctx context.Context) ([]dto.InsertCarResponse, error
 	span.LogFields(log.String("response", string(resJson)))
 
 	// return all response
-	return response, nil
+	return response, &meta, nil
+}
+
+// get all data with keyset pagination, skip count and read one extra row to know if there is next page
+func (c *CarService) getAllCursor(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error) {
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service GetAllCursor")
+	defer span.Finish()
+
+	seek := *query
+	seek.Limit++
+
+	// run query in repository
+	cars, err := c.CarRepository.GetAll(ctxTracing, nil, &seek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backward := query.After != nil && query.After.Backward
+	hasMore := len(cars) > query.Limit
+	if hasMore {
+		if backward {
+			// the extra row is the first one when reading backward
+			cars = cars[1:]
+		} else {
+			cars = cars[:query.Limit]
+		}
+	}
+
+	// convert to response
+	var response = []dto.InsertCarResponse{}
+	for i := range cars {
+		response = append(response, toCarResponse(&cars[i]))
+	}
+
+	// create page meta
+	meta := dto.PageMeta{Limit: query.Limit}
+	if hasMore || backward {
+		next, err := c.encodeCursor(query, &cars[len(cars)-1], false)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.NextCursor = &next
+	}
+	if query.After != nil && (hasMore || !backward) {
+		prev, err := c.encodeCursor(query, &cars[0], true)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.PrevCursor = &prev
+	}
+
+	// log to tracing
+	resJson, _ := json.Marshal(&response)
+	span.LogFields(log.String("response", string(resJson)))
+
+	return response, &meta, nil
+}
+
+// create signed cursor pointing at car
+func (c *CarService) encodeCursor(query *dto.CarQuery, car *entity.Car, backward bool) (string, error) {
+	cursor := dto.CarCursor{
+		Sort:     query.Sort,
+		Order:    query.Order,
+		Id:       car.Id,
+		Backward: backward,
+		Filter:   carFilterKey(query),
+	}
+
+	switch query.Sort {
+	case "name":
+		cursor.Name = car.Name
+	case "price":
+		cursor.Price = car.Price
+	case "release_date":
+		releaseDate := releaseDate(car).UTC()
+		cursor.ReleaseDate = &releaseDate
+	}
+
+	token, err := helper.EncodeCursor(c.cursorSecret(), &cursor)
+	if err != nil {
+		return "", customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	return token, nil
+}
+
+// carFilterKey return fingerprint of the active filter, so a cursor cant be reused with other filter
+func carFilterKey(query *dto.CarQuery) string {
+	filter := fmt.Sprintf("%v|%v|%v|%v|%v|%v", query.Name, query.MinPrice, query.MaxPrice, query.ReleaseFrom, query.ReleaseTo, query.Trashed)
+	sum := sha256.Sum256([]byte(filter))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func (c *CarService) cursorSecret() []byte {
+	return []byte(c.Config.GetConfig().App.CursorSecret.Value())
 }
 
 func (c *CarService) GetDetail(ctx context.Context, id int) (*dto.InsertCarResponse, error) {
@@ -141,33 +576,472 @@ func (c *CarService) GetDetail(ctx context.Context, id int) (*dto.InsertCarRespo
 
 	span.LogFields(log.Int("id", id))
 
+	// call procedure in repository, read without transaction so it can be served from cache
+	car, err := c.CarRepository.GetDetail(ctxTracing, nil, id)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// convert to response dto
+	response := toCarResponse(car)
+
+	// log to tracing
+	resJson, _ := json.Marshal(&response)
+	span.LogFields(log.String("response", string(resJson)))
+
+	return &response, nil
+}
+
+// LastModified return the latest change time of cars catalogue, used for conditional get
+func (c *CarService) LastModified(ctx context.Context) (time.Time, error) {
+	// create span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service LastModified")
+	defer span.Finish()
+
+	// read without transaction, so it can be served from cache
+	lastModified, err := c.CarRepository.LastModified(ctxTracing, nil)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return time.Time{}, err
+	}
+
+	return lastModified, nil
+}
+
+func (c *CarService) Update(ctx context.Context, id int, version int, request *dto.UpdateCarRequest) (*dto.InsertCarResponse, error) {
+	// create span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Update")
+	defer span.Finish()
+
+	request.Name = helper.NormalizeName(request.Name)
+
+	reqJson, _ := json.Marshal(&request)
+	span.LogFields(log.Int("id", id), log.Int("version", version), log.String("request", string(reqJson)))
+
+	if err := c.Validate.StructCtx(ctxTracing, *request); err != nil {
+		// return error validator
+		return nil, err
+	}
+
 	// start transaction
 	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
 	defer tx.Rollback()
+
+	input := entity.Car{
+		Id:    id,
+		Name:  request.Name,
+		Price: request.Price,
+		ReleaseDate: &sql.NullTime{
+			Time:  helper.StringToDate(request.ReleaseDate),
+			Valid: true,
+		},
+		Version: version,
+	}
+
+	if err := c.checkDuplicate(ctxTracing, tx, input.Name, input.ReleaseDate.Time, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// call procedure in repository, fail when version changed
+	car, err := c.CarRepository.Update(ctxTracing, tx, &input)
 	if err != nil {
 		span.LogFields(log.String("error", err.Error()))
-		return nil, customError.NewInternalServerError(err.Error())
+		return nil, err
 	}
 
-	// call procedure in repository
+	// success update
+	if err := c.commit(ctxTracing, tx, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := toCarResponse(car)
+
+	// log to tracing
+	resJson, _ := json.Marshal(&response)
+	span.LogFields(log.String("response", string(resJson)))
+
+	return &response, nil
+}
+
+func (c *CarService) Patch(ctx context.Context, id int, version int, request *dto.PatchCarRequest) (*dto.InsertCarResponse, error) {
+	// create span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Patch")
+	defer span.Finish()
+
+	if request.Name != nil {
+		name := helper.NormalizeName(*request.Name)
+		request.Name = &name
+	}
+
+	reqJson, _ := json.Marshal(&request)
+	span.LogFields(log.Int("id", id), log.Int("version", version), log.String("request", string(reqJson)))
+
+	if err := c.Validate.StructCtx(ctxTracing, *request); err != nil {
+		// return error validator
+		return nil, err
+	}
+
+	// create entity input, only filled field
+	input := entity.CarPatch{
+		Name:  request.Name,
+		Price: request.Price,
+	}
+	if request.ReleaseDate != nil {
+		input.ReleaseDate = &sql.NullTime{
+			Time:  helper.StringToDate(*request.ReleaseDate),
+			Valid: true,
+		}
+	}
+
+	if input.Name == nil && input.Price == nil && input.ReleaseDate == nil {
+		return nil, customError.NewBadRequestError("no field to update")
+	}
+
+	// start transaction
+	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer tx.Rollback()
+
+	// call procedure in repository, fail when version changed
+	if err := c.CarRepository.Patch(ctxTracing, tx, id, version, &input); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// get latest data after patch
 	car, err := c.CarRepository.GetDetail(ctxTracing, tx, id)
 	if err != nil {
 		span.LogFields(log.String("error", err.Error()))
 		return nil, err
 	}
 
-	// convert to response dto
-	response := dto.InsertCarResponse{
-		Id:          car.Id,
-		Name:        car.Name,
-		Price:       car.Price,
-		ReleaseDate: helper.DateToString(car.ReleaseDate.Time),
+	// patched row is checked, the transaction is rolled back when it become a duplicate
+	if input.Name != nil || input.ReleaseDate != nil {
+		if err := c.checkDuplicate(ctxTracing, tx, car.Name, releaseDate(car), id); err != nil {
+			span.LogFields(log.String("error", err.Error()))
+			return nil, err
+		}
 	}
 
+	// success patch
+	if err := c.commit(ctxTracing, tx, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := toCarResponse(car)
+
 	// log to tracing
 	resJson, _ := json.Marshal(&response)
 	span.LogFields(log.String("response", string(resJson)))
 
-	tx.Commit()
 	return &response, nil
 }
+
+func (c *CarService) Delete(ctx context.Context, id int, version int) error {
+	// create span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Delete")
+	defer span.Finish()
+
+	span.LogFields(log.Int("id", id), log.Int("version", version))
+
+	// start transaction
+	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer tx.Rollback()
+
+	// call procedure in repository, fail when version changed
+	if err := c.CarRepository.Delete(ctxTracing, tx, id, version); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return err
+	}
+
+	// success delete
+	if err := c.commit(ctxTracing, tx, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// validateRow normalize and validate one row of bulk insert or import, invalid field is reported in result
+func (c *CarService) validateRow(ctx context.Context, request *dto.InsertCarRequest, result *dto.BulkInsertCarResult) {
+	request.Name = helper.NormalizeName(request.Name)
+	if err := c.Validate.StructCtx(ctx, *request); err != nil {
+		result.Error = helper.ValidationErrorMessage(err)
+		result.Details = helper.ValidationErrorDetails(err, nil)
+		result.ValidationError = err
+	}
+}
+
+// insertAtomic insert every row in one transaction, first failure rollback all of them
+func (c *CarService) insertAtomic(ctx context.Context, requests []dto.InsertCarRequest, response *dto.BulkInsertCarResponse) error {
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service InsertAtomic")
+	defer span.Finish()
+
+	// create db transaction
+	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer tx.Rollback()
+
+	for i := range requests {
+		input := newCarEntity(&requests[i])
+		car, err := c.insertUnique(ctxTracing, tx, &input)
+		if err != nil {
+			span.LogFields(log.Int("index", i), log.String("error", err.Error()))
+			response.Results[i].Error = err.Error()
+
+			// rollback all row inserted before
+			for j := 0; j < i; j++ {
+				response.Results[j].Data = nil
+			}
+			response.Failed = countFailed(response.Results)
+			if errors.Is(err, customError.ErrConflict) {
+				return customError.NewConflictError("bulk insert rolled back : "+err.Error(), customError.WithCause(err))
+			}
+			return customError.NewInternalServerError("bulk insert rolled back : "+err.Error(), customError.WithCause(err))
+		}
+
+		data := toCarResponse(car)
+		response.Results[i].Data = &data
+	}
+
+	// success insert
+	if err := tx.Commit(); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		for i := range response.Results {
+			response.Results[i].Data = nil
+			response.Results[i].Error = "bulk insert rolled back : " + err.Error()
+		}
+		response.Failed = countFailed(response.Results)
+		return customError.NewInternalServerError("bulk insert rolled back : "+err.Error(), customError.WithCause(err))
+	}
+
+	c.CarRepository.Invalidate(ctxTracing)
+	return nil
+}
+
+// commit commit tx then drop cache of the changed cars, so cache never hold data before the commit
+func (c *CarService) commit(ctx context.Context, tx *sql.Tx, ids ...int) error {
+	if err := tx.Commit(); err != nil {
+		return customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+
+	c.CarRepository.Invalidate(ctx, ids...)
+	return nil
+}
+
+// insertRow insert one row in its own transaction, the row is only reported success after commit
+func (c *CarService) insertRow(ctx context.Context, request *dto.InsertCarRequest) (*entity.Car, error) {
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer tx.Rollback()
+
+	input := newCarEntity(request)
+	car, err := c.insertUnique(ctx, tx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.commit(ctx, tx, car.Id); err != nil {
+		return nil, err
+	}
+
+	return car, nil
+}
+
+// insertUnique insert input when no other car has the same name and release date
+func (c *CarService) insertUnique(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error) {
+	if err := c.checkDuplicate(ctx, tx, input.Name, releaseDate(input), 0); err != nil {
+		return nil, err
+	}
+
+	return c.CarRepository.Insert(ctx, tx, input)
+}
+
+// checkDuplicate return conflict when other car already use the name and release date.
+// unique index uq_cars_name_release_day_alive is the real guard, this only give a clearer message
+func (c *CarService) checkDuplicate(ctx context.Context, tx *sql.Tx, name string, date time.Time, excludeId int) error {
+	duplicate, err := c.CarRepository.IsDuplicate(ctx, tx, name, date, excludeId)
+	if err != nil {
+		return err
+	}
+
+	if duplicate {
+		message := fmt.Sprintf("car %v with release date %v already exist", name, helper.DateToString(date))
+		return customError.NewConflictError(message, customError.WithCode("car_duplicate"), customError.WithDetails(
+			customError.Detail{Field: "name", Rule: "unique", Message: message},
+			customError.Detail{Field: "release_date", Rule: "unique", Message: message},
+		))
+	}
+
+	return nil
+}
+
+func releaseDate(car *entity.Car) time.Time {
+	if car.ReleaseDate == nil {
+		return time.Time{}
+	}
+
+	return car.ReleaseDate.Time
+}
+
+// create entity car from insert request
+func newCarEntity(request *dto.InsertCarRequest) entity.Car {
+	input := entity.Car{
+		Name:  request.Name,
+		Price: request.Price,
+		ReleaseDate: &sql.NullTime{
+			Valid: false,
+		},
+	}
+
+	if request.ReleaseDate != "" {
+		input.ReleaseDate = &sql.NullTime{
+			Time:  helper.StringToDate(request.ReleaseDate),
+			Valid: true,
+		}
+	}
+
+	return input
+}
+
+// convert entity car to response
+func toCarResponse(car *entity.Car) dto.InsertCarResponse {
+	response := dto.InsertCarResponse{
+		Id:      car.Id,
+		Name:    car.Name,
+		Price:   car.Price,
+		Version: car.Version,
+	}
+
+	if car.ReleaseDate != nil && car.ReleaseDate.Valid {
+		response.ReleaseDate = helper.DateToString(car.ReleaseDate.Time)
+	}
+	if car.DeletedAt != nil && car.DeletedAt.Valid {
+		response.DeletedAt = car.DeletedAt.Time.Format(time.RFC3339)
+	}
+	if car.UpdatedAt != nil && car.UpdatedAt.Valid {
+		response.UpdatedAt = car.UpdatedAt.Time.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func countFailed(results []dto.BulkInsertCarResult) int {
+	var failed int
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+func (c *CarService) GetAllDeleted(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error) {
+	// start tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service GetAllDeleted")
+	defer span.Finish()
+
+	// same listing as GetAll, but from trash
+	query.Trashed = true
+	return c.GetAll(ctxTracing, query)
+}
+
+func (c *CarService) Restore(ctx context.Context, id int) (*dto.InsertCarResponse, error) {
+	// create span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Restore")
+	defer span.Finish()
+
+	span.LogFields(log.Int("id", id))
+
+	// start transaction
+	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer tx.Rollback()
+
+	// call procedure in repository
+	if err := c.CarRepository.Restore(ctxTracing, tx, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// get restored data
+	car, err := c.CarRepository.GetDetail(ctxTracing, tx, id)
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// another car may take the name and release date while this one in trash
+	if err := c.checkDuplicate(ctxTracing, tx, car.Name, releaseDate(car), id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	// success restore
+	if err := c.commit(ctxTracing, tx, id); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := toCarResponse(car)
+	return &response, nil
+}
+
+func (c *CarService) Purge(ctx context.Context) (int, error) {
+	// create span tracing
+	span, ctxTracing := opentracing.StartSpanFromContext(ctx, "Service Purge")
+	defer span.Finish()
+
+	retention := defaultTrashRetention
+	if trash := c.Config.GetConfig().Trash; trash != nil && trash.Retention > 0 {
+		retention = trash.Retention
+	}
+
+	span.LogFields(log.String("retention", retention.String()))
+
+	// start transaction
+	tx, err := c.DB.Begin()
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, customError.NewInternalServerError(err.Error(), customError.WithCause(err))
+	}
+	defer tx.Rollback()
+
+	// only data deleted longer than retention is removed
+	total, err := c.CarRepository.Purge(ctxTracing, tx, time.Now().Add(-retention))
+	if err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, err
+	}
+
+	// success purge
+	if err := c.commit(ctxTracing, tx); err != nil {
+		span.LogFields(log.String("error", err.Error()))
+		return 0, err
+	}
+	return total, nil
+}
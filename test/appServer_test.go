@@ -0,0 +1,62 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/server"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppServerShutdown(t *testing.T) {
+	// server register prometheus collector, so it can only be created once
+	t.Run("test shutdown fail readiness during drain then stop run server", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		cfg := &config.Config{ConfigApp: &config.ConfigApp{App: &config.App{Port: 0, DrainDelay: 300 * time.Millisecond}}}
+		appServer, err := server.NewAppServer(db, cfg, logrus.New(), nil)
+		assert.Nil(t, err)
+		router := appServer.(*server.AppServer).Router
+
+		serverErr := make(chan error, 1)
+		go func() {
+			serverErr <- appServer.RunServer()
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		response, err := router.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		start := time.Now()
+		shutdownErr := make(chan error, 1)
+		go func() {
+			shutdownErr <- appServer.Shutdown(ctx)
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		// still serving, but probe see it is not ready
+		response, err = router.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+		assert.Nil(t, <-shutdownErr)
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+
+		select {
+		case err := <-serverErr:
+			assert.Nil(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("server still running after shutdown")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheControlMiddleware(t *testing.T) {
+	cfg := &config.Config{ConfigApp: &config.ConfigApp{Http: &config.Http{CacheControl: "no-cache"}}}
+
+	app := fiber.New()
+	app.Use(middleware.CacheControlMiddleware(cfg))
+	app.Get("/ok", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+	app.Get("/own", func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderCacheControl, "no-store")
+		return ctx.SendString("ok")
+	})
+	app.Get("/error", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(http.StatusNotFound)
+	})
+	app.Post("/ok", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+
+	t.Run("test set on get", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/ok", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, "no-cache", response.Header.Get("Cache-Control"))
+	})
+	t.Run("test keep handler value", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/own", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, "no-store", response.Header.Get("Cache-Control"))
+	})
+	t.Run("test skip error and write", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/error", nil))
+		assert.Nil(t, err)
+		assert.Empty(t, response.Header.Get("Cache-Control"))
+
+		response, err = app.Test(httptest.NewRequest(http.MethodPost, "/ok", nil))
+		assert.Nil(t, err)
+		assert.Empty(t, response.Header.Get("Cache-Control"))
+	})
+}
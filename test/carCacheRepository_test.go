@@ -0,0 +1,173 @@
+package test
+
+import (
+	"cobaApp/cache"
+	"cobaApp/config"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"cobaApp/model/entity"
+	"cobaApp/repository"
+	mck "cobaApp/test/mock"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestCarCacheRepository(t *testing.T) {
+	cacheCfg := &config.Config{ConfigApp: &config.ConfigApp{Cache: &config.Cache{Enabled: true, Capacity: 10, TTL: time.Minute}}}
+	car := &entity.Car{
+		Id:    1,
+		Name:  "Toyota",
+		Price: 200,
+		ReleaseDate: &sql.NullTime{
+			Time:  helper.StringToDate("2020-10-10"),
+			Valid: true,
+		},
+		Version: 1,
+	}
+
+	t.Run("test get detail read through", func(t *testing.T) {
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+
+		// mock, database only hit once
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(car, nil).Once()
+
+		// test
+		first, err := cacheRepo.GetDetail(context.Background(), nil, 1)
+		assert.Nil(t, err)
+
+		second, err := cacheRepo.GetDetail(context.Background(), nil, 1)
+		assert.Nil(t, err)
+
+		assert.Equal(t, first.Name, second.Name)
+		assert.Equal(t, "2020-10-10", helper.DateToString(second.ReleaseDate.Time))
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test get detail not found not cached", func(t *testing.T) {
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+
+		// mock
+		carRepo.Mock.On("GetDetail", mock.Anything, 99).Return(nil, sql.ErrNoRows).Twice()
+
+		// test
+		_, err := cacheRepo.GetDetail(context.Background(), nil, 99)
+		assert.Error(t, err)
+		_, err = cacheRepo.GetDetail(context.Background(), nil, 99)
+		assert.Error(t, err)
+
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test update invalidate detail and list", func(t *testing.T) {
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+		query := &dto.CarQuery{Page: 1, Limit: 10, Sort: "id", Order: "asc"}
+
+		// mock, every read go to database twice because of the update between
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(car, nil).Twice()
+		carRepo.Mock.On("GetAll", mock.Anything, query).Return([]entity.Car{*car}, nil).Twice()
+		carRepo.Mock.On("Update", mock.Anything, mock.Anything).Return(car, nil).Once()
+
+		// test
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+		cacheRepo.GetAll(context.Background(), nil, query)
+		cacheRepo.GetAll(context.Background(), nil, query)
+
+		_, err := cacheRepo.Update(context.Background(), nil, car)
+		assert.Nil(t, err)
+
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+		cacheRepo.GetAll(context.Background(), nil, query)
+		cacheRepo.GetAll(context.Background(), nil, query)
+
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test insert invalidate count", func(t *testing.T) {
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+		query := &dto.CarQuery{Page: 1, Limit: 10, Sort: "id", Order: "asc"}
+
+		// mock
+		carRepo.Mock.On("Count", mock.Anything, query).Return(1, nil).Once()
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil)
+		carRepo.Mock.On("Count", mock.Anything, query).Return(2, nil).Once()
+
+		// test
+		total, _ := cacheRepo.Count(context.Background(), nil, query)
+		assert.Equal(t, 1, total)
+		total, _ = cacheRepo.Count(context.Background(), nil, query)
+		assert.Equal(t, 1, total)
+
+		cacheRepo.Insert(context.Background(), nil, &entity.Car{Name: "Honda"})
+
+		total, _ = cacheRepo.Count(context.Background(), nil, query)
+		assert.Equal(t, 2, total)
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test read inside transaction skip cache", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+
+		// mock, read in transaction never stored, so the read after still go to database
+		dbMock.ExpectBegin()
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(car, nil).Twice()
+
+		tx, _ := db.Begin()
+
+		// test
+		cacheRepo.GetDetail(context.Background(), tx, 1)
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test write inside transaction invalidate after commit", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(car, nil).Twice()
+		carRepo.Mock.On("Update", mock.Anything, mock.Anything).Return(car, nil).Once()
+
+		tx, _ := db.Begin()
+
+		// test, cache keep committed data until Invalidate
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+		cacheRepo.Update(context.Background(), tx, car)
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+		carRepo.Mock.AssertNumberOfCalls(t, "GetDetail", 1)
+
+		cacheRepo.Invalidate(context.Background(), 1)
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test read older than invalidate not stored", func(t *testing.T) {
+		carRepo := mck.NewCarRepositoryMock()
+		cacheRepo := repository.NewCarCacheRepository(carRepo, cache.NewLRUCache(10), cacheCfg)
+
+		// mock, a commit happen while the first read is running
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(car, nil).Once().Run(func(args mock.Arguments) {
+			cacheRepo.Invalidate(context.Background(), 1)
+		})
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(car, nil).Once()
+
+		// test
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+		cacheRepo.GetDetail(context.Background(), nil, 1)
+
+		carRepo.Mock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,376 @@
+package test
+
+import (
+	"bytes"
+	"cobaApp/config"
+	"cobaApp/customError"
+	"cobaApp/handler"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"cobaApp/model/entity"
+	"cobaApp/service"
+	mck "cobaApp/test/mock"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readCarFile read every row with the reader used by import
+func readCarFile(content string, format string) ([]helper.CarFileRow, error) {
+	reader, err := helper.NewCarFileReader(strings.NewReader(content), format)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []helper.CarFileRow
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, *row)
+	}
+}
+
+func TestReadCarFile(t *testing.T) {
+	t.Run("test read csv with line number", func(t *testing.T) {
+		content := "name,release_date,price\nToyota,2020-10-10,100\nHonda,2021-10-10,abc\n"
+
+		rows, err := readCarFile(content, helper.CarFileCSV)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(rows))
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, dto.InsertCarRequest{Name: "Toyota", Price: 100, ReleaseDate: "2020-10-10"}, rows[0].Request)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Error(t, rows[1].Err)
+	})
+	t.Run("test read csv malformed quote", func(t *testing.T) {
+		content := "name,price,release_date\nx\"y,2,2020-01-01\nToyota,1,2020-10-10\n\"Honda,1,2020-10-10\n"
+
+		rows, err := readCarFile(content, helper.CarFileCSV)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(rows))
+		assert.Equal(t, 2, rows[0].Line)
+		assert.ErrorIs(t, rows[0].Err, csv.ErrBareQuote)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Nil(t, rows[1].Err)
+		assert.ErrorIs(t, rows[2].Err, csv.ErrQuote)
+	})
+	t.Run("test read csv missing column", func(t *testing.T) {
+		rows, err := readCarFile("name,price\nToyota,1\n", helper.CarFileCSV)
+
+		assert.Nil(t, rows)
+		assert.Error(t, err)
+	})
+	t.Run("test read ndjson skip empty line", func(t *testing.T) {
+		content := "{\"name\":\"Toyota\",\"price\":1,\"release_date\":\"2020-10-10\"}\n\n{bad json}\n"
+
+		rows, err := readCarFile(content, helper.CarFileNDJSON)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(rows))
+		assert.Equal(t, 1, rows[0].Line)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Error(t, rows[1].Err)
+	})
+	t.Run("test read ndjson reject unknown field", func(t *testing.T) {
+		content := "{\"name\":\"Toyota\",\"price\":1,\"release_date\":\"2020-10-10\",\"color\":\"red\"}\n"
+
+		rows, err := readCarFile(content, helper.CarFileNDJSON)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rows))
+		assert.EqualError(t, rows[0].Err, `json: unknown field "color"`)
+	})
+	t.Run("test resolve format", func(t *testing.T) {
+		format, ok := helper.CarFileFormat("application/x-ndjson; charset=utf-8")
+		assert.True(t, ok)
+		assert.Equal(t, helper.CarFileNDJSON, format)
+
+		format, ok = helper.CarFileFormat("cars.CSV")
+		assert.True(t, ok)
+		assert.Equal(t, helper.CarFileCSV, format)
+
+		_, ok = helper.CarFileFormat("xml")
+		assert.False(t, ok)
+	})
+}
+
+func TestExportCar(t *testing.T) {
+	cars := []entity.Car{
+		{Id: 1, Name: "Toyota, Innova", Price: 1.5, ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true}},
+		{Id: 2, Name: "Honda", Price: 2, ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2021-10-10"), Valid: true}},
+	}
+
+	t.Run("test export csv", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("Iterate", mock.Anything).Return(nil, cars)
+
+		// test
+		var buffer bytes.Buffer
+		err := carService.Export(context.Background(), &buffer, helper.CarFileCSV)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "id,name,price,release_date\n1,\"Toyota, Innova\",1.5,2020-10-10\n2,Honda,2,2021-10-10\n", buffer.String())
+	})
+	t.Run("test export ndjson", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("Iterate", mock.Anything).Return(nil, cars)
+
+		// test
+		var buffer bytes.Buffer
+		err := carService.Export(context.Background(), &buffer, helper.CarFileNDJSON)
+
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		assert.Equal(t, 2, len(lines))
+		assert.JSONEq(t, `{"id":2,"name":"Honda","price":2,"release_date":"2021-10-10"}`, lines[1])
+	})
+}
+
+func TestImportCar(t *testing.T) {
+	car := &entity.Car{Id: 1, Name: "Toyota", Price: 1, ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true}}
+	content := "name,price,release_date\nToyota,1,2020-10-10\nHonda,abc,2020-10-10\n,1,2020-10-10\n"
+
+	t.Run("test import best effort report line", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Once()
+
+		// test
+		result, err := carService.Import(context.Background(), strings.NewReader(content), helper.CarFileCSV, service.BulkModeBestEffort)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, 1, result.Success)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, 3, result.Results[1].Line)
+		assert.Contains(t, result.Results[1].Error, "invalid price")
+		assert.Equal(t, 4, result.Results[2].Line)
+		assert.Contains(t, result.Results[2].Error, "name")
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test import atomic canceled on parse error", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// test
+		result, err := carService.Import(context.Background(), strings.NewReader(content), helper.CarFileCSV, "")
+
+		assert.IsType(t, &customError.BadRequestError{}, err)
+		assert.Equal(t, 2, result.Failed)
+		carRepo.Mock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test import report malformed csv line", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Once()
+
+		// test, bare quote and unterminated quote
+		content := "name,price,release_date\nx\"y,2,2020-01-01\nToyota,1,2020-10-10\n\"Honda,1,2020-10-10\n"
+		result, err := carService.Import(context.Background(), strings.NewReader(content), helper.CarFileCSV, service.BulkModeBestEffort)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, 1, result.Success)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, 2, result.Results[0].Line)
+		assert.Contains(t, result.Results[0].Error, "bare \" in non-quoted-field")
+		assert.Contains(t, result.Results[2].Error, "extraneous or missing \" in quoted-field")
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test import atomic canceled on malformed csv line", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// test
+		result, err := carService.Import(context.Background(), strings.NewReader("name,price,release_date\nx\"y,2,2020-01-01\n"), helper.CarFileCSV, "")
+
+		assert.IsType(t, &customError.BadRequestError{}, err)
+		assert.Equal(t, 1, result.Failed)
+		carRepo.Mock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test import more than bulk limit in one transaction", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		var file strings.Builder
+		file.WriteString("name,price,release_date\n")
+		for i := 0; i < 1200; i++ {
+			fmt.Fprintf(&file, "Car %v,1,2020-10-10\n", i)
+		}
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Times(1200)
+
+		// test
+		result, err := carService.Import(context.Background(), strings.NewReader(file.String()), helper.CarFileCSV, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1200, result.Total)
+		assert.Equal(t, 1200, result.Success)
+		assert.Equal(t, 1201, result.Results[1199].Line)
+		carRepo.Mock.AssertExpectations(t)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test import atomic rolled back on invalid line in later chunk", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		var file strings.Builder
+		file.WriteString("name,price,release_date\n")
+		for i := 0; i < 600; i++ {
+			fmt.Fprintf(&file, "Car %v,1,2020-10-10\n", i)
+		}
+		file.WriteString("Broken,abc,2020-10-10\n")
+
+		// mock, first chunk is inserted then rolled back
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Times(500)
+
+		// test
+		result, err := carService.Import(context.Background(), strings.NewReader(file.String()), helper.CarFileCSV, "")
+
+		assert.IsType(t, &customError.BadRequestError{}, err)
+		assert.Equal(t, 601, result.Total)
+		assert.Equal(t, 1, result.Failed)
+		assert.Nil(t, result.Results[0].Data)
+		carRepo.Mock.AssertExpectations(t)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestExportImportCarHandler(t *testing.T) {
+	t.Run("test export negotiate ndjson from accept", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Get("/", carHandler.Export)
+
+		// mock
+		carService.Mock.On("Export", mock.Anything, mock.Anything, helper.CarFileNDJSON).Return(nil, "{\"id\":1}\n")
+
+		// create request
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Accept", "application/x-ndjson")
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/x-ndjson", response.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, "{\"id\":1}\n", string(body))
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test export invalid format", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Get("/", carHandler.Export)
+
+		// receive response
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/?format=xml", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+	t.Run("test import multipart csv", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Post("/", carHandler.Import)
+
+		// mock
+		carService.Mock.On("Import", mock.Anything, mock.Anything, helper.CarFileCSV, "best_effort").
+			Return(&dto.BulkInsertCarResponse{Total: 1, Success: 1}, nil)
+
+		// create multipart request
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", "cars.csv")
+		part.Write([]byte("name,price,release_date\nToyota,1,2020-10-10\n"))
+		writer.Close()
+
+		request := httptest.NewRequest(http.MethodPost, "/?mode=best_effort", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		carService.Mock.AssertExpectations(t)
+	})
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"cobaApp/config"
 	"cobaApp/customError"
 	"cobaApp/handler"
 	"cobaApp/helper"
@@ -8,6 +9,7 @@ import (
 	mck "cobaApp/test/mock"
 	"encoding/json"
 	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"io"
@@ -15,13 +17,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestInsertCarHandler(t *testing.T) {
 	t.Run("test insert error bad request", func(t *testing.T) {
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
 		app.Post("/", carHandler.InsertData)
 
@@ -45,8 +48,8 @@ func TestInsertCarHandler(t *testing.T) {
 	})
 	t.Run("test insert error not found", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
-		app := fiber.New()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Post("/", carHandler.InsertData)
 
 		// mock
@@ -84,15 +87,15 @@ func TestInsertCarHandler(t *testing.T) {
 func TestGetAllCarHandler(t *testing.T) {
 	t.Run("test get all error not found", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/", carHandler.GetAll)
 
 		// mock
 		errMessage := "record not found"
-		carService.Mock.On("GetAll", mock.Anything).
-			Return(nil, customError.NewNotFoundError(errMessage))
+		carService.Mock.On("GetAll", mock.Anything, mock.Anything).
+			Return(nil, nil, customError.NewNotFoundError(errMessage))
 
 		// create request
 		request := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -114,14 +117,14 @@ func TestGetAllCarHandler(t *testing.T) {
 	})
 	t.Run("test get all error bad request", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/", carHandler.GetAll)
 
 		// mock
 		errMessage := "error bad request"
-		carService.Mock.On("GetAll", mock.Anything).Return(nil, customError.NewBadRequestError(errMessage))
+		carService.Mock.On("GetAll", mock.Anything, mock.Anything).Return(nil, nil, customError.NewBadRequestError(errMessage))
 
 		// create request
 		request := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -144,14 +147,14 @@ func TestGetAllCarHandler(t *testing.T) {
 	})
 	t.Run("test get all error internal server", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/", carHandler.GetAll)
 
 		// mock
 		errorMessage := "error internal server error"
-		carService.Mock.On("GetAll", mock.Anything).Return(nil, customError.NewInternalServerError(errorMessage))
+		carService.Mock.On("GetAll", mock.Anything, mock.Anything).Return(nil, nil, customError.NewInternalServerError(errorMessage))
 
 		// create request
 		request := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -175,20 +178,21 @@ func TestGetAllCarHandler(t *testing.T) {
 	})
 	t.Run("test get all success", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/", carHandler.GetAll)
 
 		// mock
-		carService.Mock.On("GetAll", mock.Anything).Return([]dto.InsertCarResponse{
+		carService.Mock.On("GetAll", mock.Anything, mock.Anything).Return([]dto.InsertCarResponse{
 			{
 				Id:          1,
 				Name:        "Toyota",
 				Price:       614000000,
 				ReleaseDate: "2020-10-10",
 			},
-		}, nil)
+		}, &dto.PageMeta{Page: 1, Limit: 10, TotalData: 1, TotalPage: 1}, nil)
+		carService.Mock.On("LastModified", mock.Anything).Return(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), nil)
 
 		// create request
 		request := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -208,6 +212,77 @@ func TestGetAllCarHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, int(responseBody["status_code"].(float64)))
 		assert.Equal(t, helper.CodeToStatus(http.StatusOK), responseBody["status"].(string))
 		assert.Equal(t, "success get all data cars", responseBody["message"].(string))
+		assert.Equal(t, float64(1), responseBody["meta"].(map[string]any)["total_data"].(float64))
+		assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", response.Header.Get("Last-Modified"))
+		assert.NotEmpty(t, response.Header.Get("ETag"))
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test get all not modified", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Get("/", carHandler.GetAll)
+
+		// mock
+		carService.Mock.On("GetAll", mock.Anything, mock.Anything).Return([]dto.InsertCarResponse{
+			{Id: 1, Name: "Toyota", Price: 614000000, ReleaseDate: "2020-10-10", Version: 1},
+		}, &dto.PageMeta{Page: 1, Limit: 10, TotalData: 1, TotalPage: 1}, nil)
+		carService.Mock.On("LastModified", mock.Anything).Return(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), nil)
+
+		// first request to get the etag
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, err)
+		etag := response.Header.Get("ETag")
+
+		// conditional request with the same etag
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Add("If-None-Match", etag)
+
+		response, err = app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotModified, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Empty(t, body)
+
+		// client copy older than last modified
+		request = httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Add("If-Modified-Since", "Mon, 01 Jan 2024 00:00:00 GMT")
+
+		response, err = app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+	t.Run("test get all parse query spec", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Get("/", carHandler.GetAll)
+
+		// mock
+		expectedQuery := &dto.CarQuery{
+			Page:        2,
+			Limit:       5,
+			Sort:        "price",
+			Order:       "desc",
+			Name:        "toyota",
+			MinPrice:    100,
+			ReleaseFrom: "2020-01-01",
+		}
+		carService.Mock.On("GetAll", mock.Anything, expectedQuery).
+			Return([]dto.InsertCarResponse{}, &dto.PageMeta{Page: 2, Limit: 5}, nil)
+		carService.Mock.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+
+		// create request
+		request := httptest.NewRequest(http.MethodGet,
+			"/?page=2&limit=5&sort=price&order=desc&name=toyota&min_price=100&release_from=2020-01-01", nil)
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
 		carService.Mock.AssertExpectations(t)
 	})
 }
@@ -215,9 +290,9 @@ func TestGetAllCarHandler(t *testing.T) {
 func TestGetDetailHandler(t *testing.T) {
 	t.Run("test get detail failed convert int", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/:id", carHandler.GetDetail)
 
 		// create request
@@ -242,9 +317,9 @@ func TestGetDetailHandler(t *testing.T) {
 	})
 	t.Run("test get detail not found", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/:id", carHandler.GetDetail)
 
 		// mock
@@ -272,9 +347,9 @@ func TestGetDetailHandler(t *testing.T) {
 	})
 	t.Run("test get detail internal server error", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/:id", carHandler.GetDetail)
 
 		// mock
@@ -303,9 +378,9 @@ func TestGetDetailHandler(t *testing.T) {
 	})
 	t.Run("test get detail success", func(t *testing.T) {
 		carService := mck.NewCarServiceMock()
-		carHandler := handler.NewCarHandler(carService)
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
 
-		app := fiber.New()
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
 		app.Get("/:id", carHandler.GetDetail)
 
 		// mock
@@ -314,6 +389,7 @@ func TestGetDetailHandler(t *testing.T) {
 			Name:        "Toyota",
 			Price:       12345,
 			ReleaseDate: "2020-10-10",
+			Version:     7,
 		}, nil)
 
 		// test -> create request
@@ -324,6 +400,7 @@ func TestGetDetailHandler(t *testing.T) {
 		assert.Nil(t, err)
 		assert.NotNil(t, response)
 		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, `"7"`, response.Header.Get("ETag"))
 
 		// receive response_body
 		body, err := io.ReadAll(response.Body)
@@ -334,4 +411,374 @@ func TestGetDetailHandler(t *testing.T) {
 		assert.Equal(t, "ok", helper.CodeToStatus(int(responseBody["status_code"].(float64))))
 		carService.Mock.AssertExpectations(t)
 	})
+	t.Run("test get detail not modified", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Get("/:id", carHandler.GetDetail)
+
+		// mock
+		carService.Mock.On("GetDetail", mock.Anything, 1).Return(&dto.InsertCarResponse{
+			Id:          1,
+			Name:        "Toyota",
+			Price:       12345,
+			ReleaseDate: "2020-10-10",
+			Version:     7,
+			UpdatedAt:   "2024-01-02T03:04:05Z",
+		}, nil)
+
+		// etag match
+		request := httptest.NewRequest(http.MethodGet, "/1", nil)
+		request.Header.Add("If-None-Match", `"7"`)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotModified, response.StatusCode)
+		assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", response.Header.Get("Last-Modified"))
+
+		// etag changed, If-Modified-Since ignored
+		request = httptest.NewRequest(http.MethodGet, "/1", nil)
+		request.Header.Add("If-None-Match", `"6"`)
+		request.Header.Add("If-Modified-Since", "Tue, 02 Jan 2024 03:04:05 GMT")
+
+		response, err = app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		// not modified since
+		request = httptest.NewRequest(http.MethodGet, "/1", nil)
+		request.Header.Add("If-Modified-Since", "Tue, 02 Jan 2024 03:04:05 GMT")
+
+		response, err = app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotModified, response.StatusCode)
+	})
+}
+
+func TestUpdateCarHandler(t *testing.T) {
+	reqJson, _ := json.Marshal(&dto.UpdateCarRequest{Name: "Toyota", Price: 1, ReleaseDate: "2020-10-10"})
+
+	t.Run("test update without if match", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Put("/:id", carHandler.UpdateData)
+
+		// create request
+		request := httptest.NewRequest(http.MethodPut, "/1", strings.NewReader(string(reqJson)))
+		request.Header.Add("Content-Type", "application/json")
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionRequired, response.StatusCode)
+		carService.Mock.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("test update version not match", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Put("/:id", carHandler.UpdateData)
+
+		// mock
+		carService.Mock.On("Update", mock.Anything, 1, 2, mock.Anything).
+			Return(nil, customError.NewPreconditionFailedError("version not match, current version is 3"))
+
+		// create request
+		request := httptest.NewRequest(http.MethodPut, "/1", strings.NewReader(string(reqJson)))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("If-Match", `"2"`)
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, "precondition failed", responseBody["status"].(string))
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test update not found", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Put("/:id", carHandler.UpdateData)
+
+		// mock
+		errMessage := "record not found"
+		carService.Mock.On("Update", mock.Anything, 99, 1, mock.Anything).
+			Return(nil, customError.NewNotFoundError(errMessage))
+
+		// create request
+		request := httptest.NewRequest(http.MethodPut, "/99", strings.NewReader(string(reqJson)))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("If-Match", `"1"`)
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, errMessage, responseBody["message"].(string))
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test update success", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Put("/:id", carHandler.UpdateData)
+
+		// mock
+		carService.Mock.On("Update", mock.Anything, 1, 1, mock.Anything).Return(&dto.InsertCarResponse{
+			Id:          1,
+			Name:        "Toyota",
+			Price:       1,
+			ReleaseDate: "2020-10-10",
+			Version:     2,
+		}, nil)
+
+		// create request
+		request := httptest.NewRequest(http.MethodPut, "/1", strings.NewReader(string(reqJson)))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("If-Match", `"1"`)
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, `"2"`, response.Header.Get("ETag"))
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, "success update data", responseBody["message"].(string))
+		carService.Mock.AssertExpectations(t)
+	})
+}
+
+func TestPatchCarHandler(t *testing.T) {
+	newApp := func(carService *mck.CarServiceMock) *fiber.App {
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Patch("/:id", carHandler.PatchData)
+		return app
+	}
+	newRequest := func(ifMatch string) *http.Request {
+		request := httptest.NewRequest(http.MethodPatch, "/1", strings.NewReader(`{"price":5}`))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("If-Match", ifMatch)
+		return request
+	}
+	price := float64(5)
+	current := &dto.InsertCarResponse{Id: 1, Name: "Toyota", Price: 1, ReleaseDate: "2020-10-10", Version: 3}
+	patched := &dto.InsertCarResponse{Id: 1, Name: "Toyota", Price: price, ReleaseDate: "2020-10-10", Version: 4}
+
+	t.Run("test patch only send price", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+
+		// mock
+		carService.Mock.On("Patch", mock.Anything, 1, 3, &dto.PatchCarRequest{Price: &price}).Return(patched, nil)
+
+		// receive response
+		response, err := newApp(carService).Test(newRequest(`"3"`))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		carService.Mock.AssertExpectations(t)
+		carService.Mock.AssertNotCalled(t, "GetDetail", mock.Anything, mock.Anything)
+	})
+	t.Run("test patch weak etag precondition failed", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+
+		// receive response
+		response, err := newApp(carService).Test(newRequest(`W/"3"`))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, response.StatusCode)
+		carService.Mock.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("test patch any version", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+
+		// mock
+		carService.Mock.On("GetDetail", mock.Anything, 1).Return(current, nil)
+		carService.Mock.On("Patch", mock.Anything, 1, 3, &dto.PatchCarRequest{Price: &price}).Return(patched, nil)
+
+		// receive response
+		response, err := newApp(carService).Test(newRequest("*"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test patch etag list", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+
+		// mock
+		carService.Mock.On("GetDetail", mock.Anything, 1).Return(current, nil)
+		carService.Mock.On("Patch", mock.Anything, 1, 3, &dto.PatchCarRequest{Price: &price}).Return(patched, nil)
+
+		// receive response
+		response, err := newApp(carService).Test(newRequest(`"1", "3"`))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test patch etag list not match", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+
+		// mock
+		carService.Mock.On("GetDetail", mock.Anything, 1).Return(current, nil)
+
+		// receive response
+		response, err := newApp(carService).Test(newRequest(`"1", "2"`))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, response.StatusCode)
+		carService.Mock.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("test patch invalid if match", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+
+		// receive response
+		response, err := newApp(carService).Test(newRequest("3"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func TestDeleteCarHandler(t *testing.T) {
+	t.Run("test delete not found", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Delete("/:id", carHandler.DeleteData)
+
+		// mock
+		carService.Mock.On("Delete", mock.Anything, 99, 1).Return(customError.NewNotFoundError("record not found"))
+
+		// create request
+		request := httptest.NewRequest(http.MethodDelete, "/99", nil)
+		request.Header.Add("If-Match", `"1"`)
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test delete success", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Delete("/:id", carHandler.DeleteData)
+
+		// mock
+		carService.Mock.On("Delete", mock.Anything, 1, 1).Return(nil)
+
+		// create request
+		request := httptest.NewRequest(http.MethodDelete, "/1", nil)
+		request.Header.Add("If-Match", `"1"`)
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, "success delete data", responseBody["message"].(string))
+		carService.Mock.AssertExpectations(t)
+	})
+}
+
+func TestInsertBulkCarHandler(t *testing.T) {
+	t.Run("test bulk atomic failed return report", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Post("/", carHandler.InsertBulk)
+
+		// mock
+		validationErr := translatedValidate.Struct(dto.InsertCarRequest{Price: 1})
+		carService.Mock.On("InsertBulk", mock.Anything, mock.Anything, "atomic").Return(&dto.BulkInsertCarResponse{
+			Mode:    "atomic",
+			Total:   1,
+			Failed:  1,
+			Results: []dto.BulkInsertCarResult{{Index: 0, Error: helper.ValidationErrorMessage(validationErr), ValidationError: validationErr}},
+		}, customError.NewBadRequestError("bulk insert canceled, some data is invalid"))
+
+		// create request
+		request := httptest.NewRequest(http.MethodPost, "/?mode=atomic", strings.NewReader(`[{"price":1}]`))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Set("Accept-Language", "id")
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		json.Unmarshal(body, &responseBody)
+
+		data := responseBody["data"].(map[string]any)
+		assert.Equal(t, float64(1), data["failed"].(float64))
+
+		// row error is translated like single insert
+		result := data["results"].([]any)[0].(map[string]any)
+		details := result["details"].([]any)
+		assert.Equal(t, "name", details[0].(map[string]any)["field"])
+		assert.Equal(t, "required", details[0].(map[string]any)["rule"])
+		assert.Equal(t, "name wajib diisi", details[0].(map[string]any)["message"])
+		assert.Equal(t, "name wajib diisi. release_date wajib diisi", result["error"])
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test bulk best effort success", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Post("/", carHandler.InsertBulk)
+
+		// mock
+		carService.Mock.On("InsertBulk", mock.Anything, mock.Anything, "best_effort").Return(&dto.BulkInsertCarResponse{
+			Mode:    "best_effort",
+			Total:   2,
+			Success: 1,
+			Failed:  1,
+		}, nil)
+
+		// create request
+		request := httptest.NewRequest(http.MethodPost, "/?mode=best_effort",
+			strings.NewReader(`[{"name":"Toyota","price":1,"release_date":"2020-10-10"},{"price":1}]`))
+		request.Header.Add("Content-Type", "application/json")
+
+		// receive response
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, "success insert 1 of 2 data", responseBody["message"].(string))
+		carService.Mock.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,494 @@
+package test
+
+import (
+	"cobaApp/customError"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"cobaApp/model/entity"
+	"cobaApp/repository"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestInsertCarRepository(t *testing.T) {
+	t.Run("test insert return last insert id and stored row", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		releaseDate := helper.StringToDate("2020-10-10")
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO cars(name, price, release_date) VALUES (?, ?, ?)")).
+			ExpectExec().
+			WithArgs("Toyota", 123.4567, releaseDate).
+			WillReturnResult(sqlmock.NewResult(42, 1))
+		dbMock.ExpectPrepare(regexp.QuoteMeta("SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE id=? AND deleted_at IS NULL")).
+			ExpectQuery().
+			WithArgs(42).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(42, "Toyota", 123.457, releaseDate, nil, 1, time.Now()))
+
+		tx, _ := db.Begin()
+
+		// test
+		car, err := carRepo.Insert(context.Background(), tx, &entity.Car{
+			Name:        "Toyota",
+			Price:       123.4567,
+			ReleaseDate: &sql.NullTime{Time: releaseDate, Valid: true},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 42, car.Id)
+		assert.Equal(t, 123.457, car.Price)
+		assert.Equal(t, releaseDate, car.ReleaseDate.Time)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test insert without release date use column default", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		defaultDate := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO cars(name, price) VALUES (?, ?)")).
+			ExpectExec().
+			WithArgs("Honda", float64(1)).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+		dbMock.ExpectPrepare(regexp.QuoteMeta("SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE id=? AND deleted_at IS NULL")).
+			ExpectQuery().
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(7, "Honda", 1, defaultDate, nil, 1, time.Now()))
+
+		tx, _ := db.Begin()
+
+		// test
+		car, err := carRepo.Insert(context.Background(), tx, &entity.Car{
+			Name:        "Honda",
+			Price:       1,
+			ReleaseDate: &sql.NullTime{Valid: false},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 7, car.Id)
+		assert.True(t, car.ReleaseDate.Valid)
+		assert.Equal(t, defaultDate, car.ReleaseDate.Time)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test insert error exec", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO cars")).
+			ExpectExec().
+			WillReturnError(sql.ErrConnDone)
+
+		tx, _ := db.Begin()
+
+		// test
+		car, err := carRepo.Insert(context.Background(), tx, &entity.Car{
+			Name:        "Honda",
+			Price:       1,
+			ReleaseDate: &sql.NullTime{Time: time.Now(), Valid: true},
+		})
+
+		assert.Nil(t, car)
+		assert.IsType(t, &customError.InternalServerError{}, err)
+	})
+	t.Run("test insert duplicate entry is conflict", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock, other transaction insert the same car after the duplicate check
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO cars")).
+			ExpectExec().
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'Honda-2020-10-10-1' for key 'uq_cars_name_release_day_alive'"})
+
+		tx, _ := db.Begin()
+
+		// test
+		car, err := carRepo.Insert(context.Background(), tx, &entity.Car{
+			Name:        "Honda",
+			Price:       1,
+			ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true},
+		})
+
+		assert.Nil(t, car)
+		assert.ErrorIs(t, err, customError.ErrConflict)
+		assert.Equal(t, "car_duplicate", customError.Code(err))
+	})
+}
+
+func TestGetAllCarRepository(t *testing.T) {
+	t.Run("test get all with filter and offset", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta(
+			"SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE deleted_at IS NULL AND name LIKE ? ESCAPE '\\\\' AND price >= ? ORDER BY price desc, id desc LIMIT ? OFFSET ?")).
+			ExpectQuery().
+			WithArgs("%toyota%", float64(100), 5, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(1, "Toyota", 200, time.Now(), nil, 1, time.Now()))
+
+		tx, _ := db.Begin()
+
+		// test
+		cars, err := carRepo.GetAll(context.Background(), tx, &dto.CarQuery{
+			Page:     2,
+			Limit:    5,
+			Sort:     "price",
+			Order:    "desc",
+			Name:     "toyota",
+			MinPrice: 100,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(cars))
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test get all name filter match wildcard literally", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectPrepare(regexp.QuoteMeta(
+			"SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE deleted_at IS NULL AND name LIKE ? ESCAPE '\\\\' ORDER BY id asc, id asc LIMIT ? OFFSET ?")).
+			ExpectQuery().
+			WithArgs(`%100\%\_a\\b%`, 10, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(1, `Sale 100%_a\b`, 200, time.Now(), nil, 1, time.Now()))
+
+		// test
+		cars, err := carRepo.GetAll(context.Background(), nil, &dto.CarQuery{
+			Page:  1,
+			Limit: 10,
+			Sort:  "id",
+			Order: "asc",
+			Name:  `100%_a\b`,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(cars))
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test get all trashed", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		deletedAt := time.Now()
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta(
+			"SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE deleted_at IS NOT NULL ORDER BY id asc, id asc LIMIT ? OFFSET ?")).
+			ExpectQuery().
+			WithArgs(10, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(1, "Toyota", 200, time.Now(), deletedAt, 2, time.Now()))
+
+		tx, _ := db.Begin()
+
+		// test
+		cars, err := carRepo.GetAll(context.Background(), tx, &dto.CarQuery{
+			Page:    1,
+			Limit:   10,
+			Sort:    "id",
+			Order:   "asc",
+			Trashed: true,
+		})
+
+		assert.Nil(t, err)
+		assert.True(t, cars[0].DeletedAt.Valid)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test get all seek after cursor", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta(
+			"SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE deleted_at IS NULL AND (name > ? OR (name = ? AND id > ?)) ORDER BY name asc, id asc LIMIT ?")).
+			ExpectQuery().
+			WithArgs("Brio", "Brio", 2, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(3, "Civic", 1, time.Now(), nil, 1, time.Now()))
+
+		tx, _ := db.Begin()
+
+		// test
+		cars, err := carRepo.GetAll(context.Background(), tx, &dto.CarQuery{
+			Limit: 3,
+			Sort:  "name",
+			Order: "asc",
+			After: &dto.CarCursor{Sort: "name", Order: "asc", Name: "Brio", Id: 2},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, cars[0].Id)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteCarRepository(t *testing.T) {
+	deleteQuery := regexp.QuoteMeta(
+		"UPDATE cars SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE id=? AND version=? AND deleted_at IS NULL")
+	versionQuery := regexp.QuoteMeta("SELECT version FROM cars WHERE id=? AND deleted_at IS NULL")
+
+	t.Run("test delete not found", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(deleteQuery).
+			ExpectExec().
+			WithArgs(99, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(versionQuery).
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+
+		tx, _ := db.Begin()
+
+		// test
+		err := carRepo.Delete(context.Background(), tx, 99, 1)
+
+		assert.IsType(t, &customError.NotFoundError{}, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test delete version not match", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(deleteQuery).
+			ExpectExec().
+			WithArgs(1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(versionQuery).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+
+		tx, _ := db.Begin()
+
+		// test
+		err := carRepo.Delete(context.Background(), tx, 1, 1)
+
+		assert.IsType(t, &customError.PreconditionFailedError{}, err)
+		assert.Equal(t, "version not match, current version is 3", err.Error())
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test delete version not match without transaction", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectPrepare(deleteQuery).
+			ExpectExec().
+			WithArgs(1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(versionQuery).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+
+		// test
+		err := carRepo.Delete(context.Background(), nil, 1, 1)
+
+		assert.IsType(t, &customError.PreconditionFailedError{}, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateCarRepository(t *testing.T) {
+	t.Run("test update with version guard", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		releaseDate := helper.StringToDate("2021-01-01")
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta(
+			"UPDATE cars SET name=?, price=?, release_date=?, version=version+1 WHERE id=? AND version=? AND deleted_at IS NULL")).
+			ExpectExec().
+			WithArgs("Honda", float64(2), releaseDate, 1, 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectPrepare(regexp.QuoteMeta("SELECT id, name, price, release_date, deleted_at, version, updated_at FROM cars WHERE id=? AND deleted_at IS NULL")).
+			ExpectQuery().
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "release_date", "deleted_at", "version", "updated_at"}).
+				AddRow(1, "Honda", 2, releaseDate, nil, 5, time.Now()))
+
+		tx, _ := db.Begin()
+
+		// test
+		car, err := carRepo.Update(context.Background(), tx, &entity.Car{
+			Id:          1,
+			Name:        "Honda",
+			Price:       2,
+			ReleaseDate: &sql.NullTime{Time: releaseDate, Valid: true},
+			Version:     4,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, car.Version)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestTrashCarRepository(t *testing.T) {
+	t.Run("test restore not in trash", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("UPDATE cars SET deleted_at=NULL, version=version+1 WHERE id=? AND deleted_at IS NOT NULL")).
+			ExpectExec().
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		tx, _ := db.Begin()
+
+		// test
+		err := carRepo.Restore(context.Background(), tx, 1)
+
+		assert.IsType(t, &customError.NotFoundError{}, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test purge deleted before", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		before := time.Now().Add(-time.Hour)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("DELETE FROM cars WHERE deleted_at IS NOT NULL AND deleted_at < ?")).
+			ExpectExec().
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		tx, _ := db.Begin()
+
+		// test
+		total, err := carRepo.Purge(context.Background(), tx, before)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestLastModifiedCarRepository(t *testing.T) {
+	t.Run("test last modified", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(regexp.QuoteMeta("SELECT MAX(updated_at) FROM cars")).
+			ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(updatedAt))
+
+		tx, _ := db.Begin()
+
+		// test
+		lastModified, err := carRepo.LastModified(context.Background(), tx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, updatedAt, lastModified)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestIsDuplicateRepository(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT COUNT(id) FROM cars WHERE name = ? AND release_day = ? AND alive = 1 AND id <> ? FOR UPDATE")
+
+	t.Run("test duplicate match whole release day", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+		day := helper.StringToDate("2020-10-10")
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(query).
+			ExpectQuery().
+			WithArgs("Toyota", "2020-10-10", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(1))
+
+		tx, _ := db.Begin()
+
+		// test
+		duplicate, err := carRepo.IsDuplicate(context.Background(), tx, "Toyota", day.Add(15*time.Hour), 3)
+
+		assert.Nil(t, err)
+		assert.True(t, duplicate)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test not duplicate", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := repository.NewCarRepository(db)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectPrepare(query).
+			ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(0))
+
+		tx, _ := db.Begin()
+
+		// test
+		duplicate, err := carRepo.IsDuplicate(context.Background(), tx, "Toyota", helper.StringToDate("2020-10-10"), 0)
+
+		assert.Nil(t, err)
+		assert.False(t, duplicate)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
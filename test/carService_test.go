@@ -1,6 +1,7 @@
 package test
 
 import (
+	"cobaApp/config"
 	"cobaApp/customError"
 	"cobaApp/helper"
 	"cobaApp/model/dto"
@@ -9,26 +10,32 @@ import (
 	mck "cobaApp/test/mock"
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
-var validate = validator.New()
+var validate = helper.NewValidator()
+var cfg = &config.Config{ConfigApp: &config.ConfigApp{
+	App: &config.App{CursorSecret: "secret"},
+}}
 
 func TestInsertCar(t *testing.T) {
 	t.Run("test insert error cant insert", func(t *testing.T) {
 		db, dbMock, _ := sqlmock.New()
 		defer db.Close()
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// mock
 		dbMock.ExpectBegin()
 		dbMock.ExpectRollback()
 		errMessage := "error when add new data"
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
 		carRepo.Mock.On("Insert", mock.Anything, mock.Anything, mock.Anything).Return(nil, customError.NewInternalServerError(errMessage))
 
 		// test
@@ -47,11 +54,12 @@ func TestInsertCar(t *testing.T) {
 		db, dbMock, _ := sqlmock.New()
 		defer db.Close()
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// mock
 		dbMock.ExpectBegin()
 		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
 		carRepo.Mock.On("Insert", mock.Anything, mock.Anything, mock.Anything).Return(&entity.Car{
 			Id:    1,
 			Name:  "Toyota",
@@ -80,7 +88,7 @@ func TestInsertCar(t *testing.T) {
 		defer db.Close()
 
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// test
 		result, err := carService.Insert(context.Background(), &dto.InsertCarRequest{
@@ -98,36 +106,34 @@ func TestInsertCar(t *testing.T) {
 
 func TestGetAllCar(t *testing.T) {
 	t.Run("test get all not found", func(t *testing.T) {
-		db, dbMock, _ := sqlmock.New()
+		db, _, _ := sqlmock.New()
 		defer db.Close()
 
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// mock
-		dbMock.ExpectBegin()
-		dbMock.ExpectRollback()
 		errMessage := "record not found"
+		carRepo.Mock.On("Count", mock.Anything, mock.Anything).Return(0, nil)
 		carRepo.Mock.On("GetAll", mock.Anything, mock.Anything).Return(nil, customError.NewNotFoundError(errMessage))
 
 		// test
-		cars, err := carService.GetAll(context.Background())
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{})
 		assert.Nil(t, cars)
+		assert.Nil(t, meta)
 		assert.NotNil(t, err)
 		assert.Error(t, err)
 		assert.Equal(t, errMessage, err.Error())
 		carRepo.Mock.AssertExpectations(t)
 	})
 	t.Run("test get all cars success", func(t *testing.T) {
-		db, dbMock, _ := sqlmock.New()
+		db, _, _ := sqlmock.New()
 		defer db.Close()
 
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// mock
-		dbMock.ExpectBegin()
-		dbMock.ExpectCommit()
 		response := []entity.Car{
 			{
 				Id:    1,
@@ -148,29 +154,67 @@ func TestGetAllCar(t *testing.T) {
 				},
 			},
 		}
+		carRepo.Mock.On("Count", mock.Anything, mock.Anything).Return(5, nil)
 		carRepo.Mock.On("GetAll", mock.Anything, mock.Anything).
 			Return(response, nil)
 
 		// test
-		cars, err := carService.GetAll(context.Background())
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{Limit: 2})
 
 		assert.Nil(t, err)
 		assert.NotNil(t, cars)
 		assert.Equal(t, 2, len(cars))
+		assert.Equal(t, 5, meta.TotalData)
+		assert.Equal(t, 3, meta.TotalPage)
+		assert.Equal(t, 2, *meta.NextPage)
+		assert.Nil(t, meta.PrevPage)
+	})
+	t.Run("test get all default query spec", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		expectedQuery := &dto.CarQuery{Page: 1, Limit: 10, Sort: "id", Order: "asc"}
+		carRepo.Mock.On("Count", mock.Anything, expectedQuery).Return(0, nil)
+		carRepo.Mock.On("GetAll", mock.Anything, expectedQuery).Return([]entity.Car{}, nil)
+
+		// test
+		_, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, meta.Page)
+		assert.Nil(t, meta.NextPage)
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test get all invalid sort", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// test
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{Sort: "id; DROP TABLE cars"})
+
+		assert.Nil(t, cars)
+		assert.Nil(t, meta)
+		assert.IsType(t, validator.ValidationErrors{}, err)
+		carRepo.Mock.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
 	})
 }
 
 func TestGetDetailCar(t *testing.T) {
 	t.Run("test get detail not found", func(t *testing.T) {
-		db, dbMock, _ := sqlmock.New()
+		db, _, _ := sqlmock.New()
 		defer db.Close()
 
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// mock
-		dbMock.ExpectBegin()
-		dbMock.ExpectRollback()
 
 		errMessage := "record not found"
 		carRepo.Mock.On("GetDetail", mock.Anything, mock.Anything, mock.Anything).
@@ -185,15 +229,13 @@ func TestGetDetailCar(t *testing.T) {
 		assert.Equal(t, errMessage, err.Error())
 	})
 	t.Run("test get detail success", func(t *testing.T) {
-		db, dbMock, _ := sqlmock.New()
+		db, _, _ := sqlmock.New()
 		defer db.Close()
 
 		carRepo := mck.NewCarRepositoryMock()
-		carService := service.NewCarService(db, validate, carRepo)
+		carService := service.NewCarService(db, validate, carRepo, cfg)
 
 		// mock
-		dbMock.ExpectBegin()
-		dbMock.ExpectCommit()
 
 		carRepo.Mock.On("GetDetail", mock.Anything, mock.Anything, mock.Anything).
 			Return(&entity.Car{
@@ -214,3 +256,670 @@ func TestGetDetailCar(t *testing.T) {
 		assert.Equal(t, "Toyota", car.Name)
 	})
 }
+
+func TestUpdateCar(t *testing.T) {
+	t.Run("test update version not match", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Update", mock.Anything, mock.MatchedBy(func(car *entity.Car) bool {
+			return car.Id == 1 && car.Version == 2
+		})).Return(nil, customError.NewPreconditionFailedError("version not match, current version is 3"))
+
+		// test
+		car, err := carService.Update(context.Background(), 1, 2, &dto.UpdateCarRequest{
+			Name:        "Toyota",
+			Price:       1,
+			ReleaseDate: "2020-10-10",
+		})
+
+		assert.Nil(t, car)
+		assert.IsType(t, &customError.PreconditionFailedError{}, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test update success", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+
+		car := &entity.Car{
+			Id:    1,
+			Name:  "Honda",
+			Price: 2,
+			ReleaseDate: &sql.NullTime{
+				Time:  helper.StringToDate("2021-01-01"),
+				Valid: true,
+			},
+			Version: 2,
+		}
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Update", mock.Anything, mock.Anything).Return(car, nil)
+
+		// test
+		result, err := carService.Update(context.Background(), 1, 1, &dto.UpdateCarRequest{
+			Name:        "Honda",
+			Price:       2,
+			ReleaseDate: "2021-01-01",
+		})
+
+		assert.Nil(t, err)
+		assert.NotNil(t, result)
+		assert.Equal(t, "Honda", result.Name)
+		assert.Equal(t, "2021-01-01", result.ReleaseDate)
+		assert.Equal(t, 2, result.Version)
+		carRepo.Mock.AssertExpectations(t)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestPatchCar(t *testing.T) {
+	t.Run("test patch without field", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// test
+		car, err := carService.Patch(context.Background(), 1, 1, &dto.PatchCarRequest{})
+
+		assert.Nil(t, car)
+		assert.Error(t, err)
+		assert.IsType(t, &customError.BadRequestError{}, err)
+	})
+	t.Run("test patch invalid price", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// test
+		price := float64(0)
+		car, err := carService.Patch(context.Background(), 1, 1, &dto.PatchCarRequest{Price: &price})
+
+		assert.Nil(t, car)
+		assert.Error(t, err)
+		assert.IsType(t, validator.ValidationErrors{}, err)
+	})
+	t.Run("test patch not found", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+
+		name := "Toyota"
+		carRepo.Mock.On("Patch", mock.Anything, 99, 1, mock.Anything).
+			Return(customError.NewNotFoundError("record not found"))
+
+		// test
+		car, err := carService.Patch(context.Background(), 99, 1, &dto.PatchCarRequest{Name: &name})
+
+		assert.Nil(t, car)
+		assert.IsType(t, &customError.NotFoundError{}, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test patch success", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+
+		price := float64(700000000)
+		carRepo.Mock.On("Patch", mock.Anything, 1, 1, &entity.CarPatch{Price: &price}).Return(nil)
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(&entity.Car{
+			Id:    1,
+			Name:  "Toyota",
+			Price: price,
+			ReleaseDate: &sql.NullTime{
+				Time:  helper.StringToDate("2020-10-10"),
+				Valid: true,
+			},
+			Version: 2,
+		}, nil)
+
+		// test
+		result, err := carService.Patch(context.Background(), 1, 1, &dto.PatchCarRequest{Price: &price})
+
+		assert.Nil(t, err)
+		assert.NotNil(t, result)
+		assert.Equal(t, price, result.Price)
+		assert.Equal(t, "Toyota", result.Name)
+		assert.Equal(t, 2, result.Version)
+		carRepo.Mock.AssertExpectations(t)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteCar(t *testing.T) {
+	t.Run("test delete not found", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+
+		errMessage := "record not found"
+		carRepo.Mock.On("Delete", mock.Anything, 99, 1).Return(customError.NewNotFoundError(errMessage))
+
+		// test
+		err := carService.Delete(context.Background(), 99, 1)
+
+		assert.Error(t, err)
+		assert.Equal(t, errMessage, err.Error())
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test delete success", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("Delete", mock.Anything, 1, 1).Return(nil)
+
+		// test
+		err := carService.Delete(context.Background(), 1, 1)
+
+		assert.Nil(t, err)
+		carRepo.Mock.AssertExpectations(t)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAllCarCursor(t *testing.T) {
+	newCar := func(id int, name string) entity.Car {
+		return entity.Car{
+			Id:    id,
+			Name:  name,
+			Price: 1,
+			ReleaseDate: &sql.NullTime{
+				Time:  helper.StringToDate("2020-10-10"),
+				Valid: true,
+			},
+		}
+	}
+
+	// nextCursor read first page sorted by name with the filter and return its next cursor
+	nextCursor := func(t *testing.T, query *dto.CarQuery) string {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		carRepo.Mock.On("GetAll", mock.Anything, mock.Anything).
+			Return([]entity.Car{newCar(1, "Avanza"), newCar(2, "Brio"), newCar(3, "Civic")}, nil)
+
+		query.Pagination, query.Limit, query.Sort = "cursor", 2, "name"
+		_, meta, err := carService.GetAll(context.Background(), query)
+		assert.Nil(t, err)
+
+		return *meta.NextCursor
+	}
+
+	t.Run("test get all cursor first page", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock, repository read limit + 1 row
+		carRepo.Mock.On("GetAll", mock.Anything, mock.MatchedBy(func(query *dto.CarQuery) bool {
+			return query.Limit == 3 && query.After == nil
+		})).Return([]entity.Car{newCar(1, "Avanza"), newCar(2, "Brio"), newCar(3, "Civic")}, nil)
+
+		// test
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{
+			Pagination: "cursor",
+			Limit:      2,
+			Sort:       "name",
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(cars))
+		assert.NotNil(t, meta.NextCursor)
+		assert.Nil(t, meta.PrevCursor)
+		carRepo.Mock.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+
+		var cursor dto.CarCursor
+		assert.Nil(t, helper.DecodeCursor([]byte("secret"), *meta.NextCursor, &cursor))
+		assert.Equal(t, "Brio", cursor.SeekValue())
+		assert.Equal(t, 2, cursor.Id)
+		assert.NotEmpty(t, cursor.Filter)
+	})
+	t.Run("test get all cursor next page", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		token := nextCursor(t, &dto.CarQuery{})
+
+		// mock
+		carRepo.Mock.On("GetAll", mock.Anything, mock.MatchedBy(func(query *dto.CarQuery) bool {
+			return query.After != nil && query.After.Id == 2 && query.Sort == "name"
+		})).Return([]entity.Car{newCar(3, "Civic")}, nil)
+
+		// test
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{Cursor: token, Limit: 2})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(cars))
+		assert.Nil(t, meta.NextCursor)
+		assert.NotNil(t, meta.PrevCursor)
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test get all cursor with other filter", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		token := nextCursor(t, &dto.CarQuery{Name: "a"})
+
+		// test
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{Cursor: token, Name: "b"})
+
+		assert.Nil(t, cars)
+		assert.Nil(t, meta)
+		assert.IsType(t, &customError.BadRequestError{}, err)
+		carRepo.Mock.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test get all cursor keep release date type", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		carRepo.Mock.On("GetAll", mock.Anything, mock.Anything).
+			Return([]entity.Car{newCar(1, "Avanza"), newCar(2, "Brio")}, nil)
+
+		// test
+		_, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{Pagination: "cursor", Limit: 1, Sort: "release_date"})
+		assert.Nil(t, err)
+
+		var cursor dto.CarCursor
+		assert.Nil(t, helper.DecodeCursor([]byte("secret"), *meta.NextCursor, &cursor))
+		assert.True(t, helper.StringToDate("2020-10-10").Equal(cursor.SeekValue().(time.Time)))
+	})
+	t.Run("test get all cursor tampered", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		token, _ := helper.EncodeCursor([]byte("other secret"), &dto.CarCursor{Sort: "name", Order: "asc", Name: "Brio", Id: 2})
+
+		// test
+		cars, meta, err := carService.GetAll(context.Background(), &dto.CarQuery{Cursor: token})
+
+		assert.Nil(t, cars)
+		assert.Nil(t, meta)
+		assert.IsType(t, &customError.BadRequestError{}, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestInsertBulkCar(t *testing.T) {
+	requests := []dto.InsertCarRequest{
+		{Name: "Toyota", Price: 1, ReleaseDate: "2020-10-10"},
+		{Name: "", Price: 1, ReleaseDate: "2020-10-10"},
+		{Name: "Honda", Price: 2, ReleaseDate: "2021-10-10"},
+	}
+	car := &entity.Car{
+		Id:          1,
+		Name:        "Toyota",
+		Price:       1,
+		ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true},
+	}
+
+	t.Run("test bulk atomic invalid row", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// test
+		result, err := carService.InsertBulk(context.Background(), requests, service.BulkModeAtomic)
+
+		assert.IsType(t, &customError.BadRequestError{}, err)
+		assert.Equal(t, 1, result.Failed)
+		assert.NotEmpty(t, result.Results[1].Error)
+		assert.False(t, result.Results[0].Success)
+		carRepo.Mock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test bulk atomic rollback when insert failed", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Once()
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).
+			Return(nil, customError.NewInternalServerError("duplicate")).Once()
+
+		// test
+		result, err := carService.InsertBulk(context.Background(), []dto.InsertCarRequest{requests[0], requests[2]}, "")
+
+		assert.IsType(t, &customError.InternalServerError{}, err)
+		assert.Equal(t, service.BulkModeAtomic, result.Mode)
+		assert.Nil(t, result.Results[0].Data)
+		assert.Equal(t, "duplicate", result.Results[1].Error)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test bulk best effort report per row", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock, one transaction per valid row
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Twice()
+
+		// test
+		result, err := carService.InsertBulk(context.Background(), requests, service.BulkModeBestEffort)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, 2, result.Success)
+		assert.Equal(t, 1, result.Failed)
+		assert.True(t, result.Results[0].Success)
+		assert.False(t, result.Results[1].Success)
+		assert.True(t, result.Results[2].Success)
+		carRepo.Mock.AssertExpectations(t)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test bulk best effort commit fail only lose its row", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit().WillReturnError(errors.New("deadlock"))
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything).Return(car, nil).Twice()
+
+		// test
+		result, err := carService.InsertBulk(context.Background(), requests, service.BulkModeBestEffort)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.Success)
+		assert.False(t, result.Results[0].Success)
+		assert.Nil(t, result.Results[0].Data)
+		assert.Contains(t, result.Results[0].Error, "deadlock")
+		assert.True(t, result.Results[2].Success)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test bulk invalid mode", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carService := service.NewCarService(db, validate, mck.NewCarRepositoryMock(), cfg)
+
+		// test
+		result, err := carService.InsertBulk(context.Background(), requests, "sometimes")
+
+		assert.Nil(t, result)
+		assert.IsType(t, &customError.BadRequestError{}, err)
+	})
+}
+
+func TestTrashCar(t *testing.T) {
+	t.Run("test get all deleted use trashed query", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		trashed := mock.MatchedBy(func(query *dto.CarQuery) bool { return query.Trashed })
+		carRepo.Mock.On("Count", mock.Anything, trashed).Return(1, nil)
+		carRepo.Mock.On("GetAll", mock.Anything, trashed).Return([]entity.Car{{
+			Id:          1,
+			Name:        "Toyota",
+			Price:       1,
+			ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true},
+			DeletedAt:   &sql.NullTime{Time: deletedAt, Valid: true},
+		}}, nil)
+
+		// test
+		cars, _, err := carService.GetAllDeleted(context.Background(), &dto.CarQuery{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "2024-01-02T03:04:05Z", cars[0].DeletedAt)
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test restore success", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		carRepo.Mock.On("Restore", mock.Anything, 1).Return(nil)
+		carRepo.Mock.On("GetDetail", mock.Anything, 1).Return(&entity.Car{
+			Id:          1,
+			Name:        "Toyota",
+			Price:       1,
+			ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true},
+		}, nil)
+
+		// test
+		car, err := carService.Restore(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, car.Id)
+		assert.Empty(t, car.DeletedAt)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test purge use retention from config", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		carRepo := mck.NewCarRepositoryMock()
+		trashCfg := &config.Config{ConfigApp: &config.ConfigApp{
+			App:   &config.App{},
+			Trash: &config.Trash{Retention: 48 * time.Hour},
+		}}
+		carService := service.NewCarService(db, validate, carRepo, trashCfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("Purge", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= 48*time.Hour && time.Since(before) < 49*time.Hour
+		})).Return(2, nil)
+
+		// test
+		total, err := carService.Purge(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, total)
+		carRepo.Mock.AssertExpectations(t)
+	})
+}
+
+func TestInsertCarValidation(t *testing.T) {
+	t.Run("test insert invalid release date", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+		carService := service.NewCarService(db, validate, mck.NewCarRepositoryMock(), cfg)
+
+		for releaseDate, rule := range map[string]string{
+			"2020-13-45": "date",
+			"tomorrow":   "date",
+			"1885-12-31": "min_year",
+			time.Now().AddDate(0, 0, 731).Format(helper.DateLayout): "max_future_days",
+		} {
+			result, err := carService.Insert(context.Background(), &dto.InsertCarRequest{
+				Name:        "Toyota",
+				Price:       500000000,
+				ReleaseDate: releaseDate,
+			})
+
+			var validationErrors validator.ValidationErrors
+			assert.Nil(t, result)
+			assert.ErrorAs(t, err, &validationErrors, releaseDate)
+			assert.Equal(t, "release_date", validationErrors[0].Field(), releaseDate)
+			assert.Equal(t, rule, validationErrors[0].Tag(), releaseDate)
+		}
+	})
+	t.Run("test insert normalize name", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, "Toyota Avanza", mock.Anything, 0).Return(false, nil)
+		carRepo.Mock.On("Insert", mock.Anything, mock.Anything, mock.Anything).Return(&entity.Car{
+			Id:          1,
+			Name:        "Toyota Avanza",
+			Price:       500000000,
+			ReleaseDate: &sql.NullTime{Time: helper.StringToDate("2020-10-10"), Valid: true},
+		}, nil)
+
+		// test
+		result, err := carService.Insert(context.Background(), &dto.InsertCarRequest{
+			Name:        "  Toyota \t Avanza ",
+			Price:       500000000,
+			ReleaseDate: "2020-10-10",
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Toyota Avanza", result.Name)
+		carRepo.Mock.AssertExpectations(t)
+	})
+	t.Run("test insert blank name", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+		carService := service.NewCarService(db, validate, mck.NewCarRepositoryMock(), cfg)
+
+		result, err := carService.Insert(context.Background(), &dto.InsertCarRequest{
+			Name:        "   ",
+			Price:       500000000,
+			ReleaseDate: "2020-10-10",
+		})
+
+		var validationErrors validator.ValidationErrors
+		assert.Nil(t, result)
+		assert.ErrorAs(t, err, &validationErrors)
+		assert.Equal(t, "name", validationErrors[0].Field())
+	})
+	t.Run("test insert duplicate conflict", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, "Toyota", helper.StringToDate("2020-10-10"), 0).Return(true, nil)
+
+		// test
+		result, err := carService.Insert(context.Background(), &dto.InsertCarRequest{
+			Name:        "Toyota",
+			Price:       500000000,
+			ReleaseDate: "2020-10-10",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, customError.ErrConflict)
+		assert.Equal(t, "car_duplicate", customError.Code(err))
+		assert.Len(t, customError.Details(err), 2)
+		carRepo.Mock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything, mock.Anything)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateCarDuplicate(t *testing.T) {
+	t.Run("test update to other car name and release date", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+		carRepo := mck.NewCarRepositoryMock()
+		carService := service.NewCarService(db, validate, carRepo, cfg)
+
+		// mock
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		carRepo.Mock.On("IsDuplicate", mock.Anything, "Honda Jazz", mock.Anything, 1).Return(true, nil)
+
+		// test
+		result, err := carService.Update(context.Background(), 1, 1, &dto.UpdateCarRequest{
+			Name:        "Honda  Jazz",
+			Price:       300000000,
+			ReleaseDate: "2019-05-05",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, customError.ErrConflict)
+		carRepo.Mock.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+}
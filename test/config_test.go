@@ -0,0 +1,285 @@
+package test
+
+import (
+	"cobaApp/config"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("test default without config file", func(t *testing.T) {
+		t.Setenv("COBAAPP_APP_CURSOR_SECRET", "secret")
+
+		cfg, err := config.LoadConfig(nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5005, cfg.GetConfig().App.Port)
+		assert.Equal(t, 50, cfg.GetConfig().Database.MaxOpenConns)
+		assert.Equal(t, 30*time.Minute, cfg.GetConfig().Database.ConnMaxLifetime)
+		assert.Equal(t, "const", cfg.GetConfig().Jaeger.SamplerType)
+		assert.Equal(t, "debug", cfg.GetConfig().Log.Level)
+	})
+	t.Run("test explicit config file not found", func(t *testing.T) {
+		cfg, err := config.LoadConfig([]string{"--config", filepath.Join(t.TempDir(), "missing.json")})
+
+		assert.Nil(t, cfg)
+		assert.Error(t, err)
+	})
+	t.Run("test precedence flag over env over file", func(t *testing.T) {
+		path := writeConfigFile(t, `{
+			"app" : {"port" : 7000, "cursor_secret" : "secret"},
+			"database" : {"host" : "file-host", "user" : "file-user", "password" : "file-pass"}
+		}`)
+		t.Setenv("COBAAPP_CONFIG", path)
+		t.Setenv("COBAAPP_DATABASE_HOST", "env-host")
+		t.Setenv("COBAAPP_DATABASE_USER", "env-user")
+		t.Setenv("COBAAPP_JAEGER_SAMPLER_TYPE", "probabilistic")
+		t.Setenv("COBAAPP_JAEGER_SAMPLER_PARAM", "0.25")
+
+		cfg, err := config.LoadConfig([]string{"--database.user", "flag-user", "--log.level=info"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 7000, cfg.GetConfig().App.Port)
+		assert.Equal(t, "file-pass", cfg.GetConfig().Database.Password.Value())
+		assert.Equal(t, "env-host", cfg.GetConfig().Database.Host)
+		assert.Equal(t, "flag-user", cfg.GetConfig().Database.User)
+		assert.Equal(t, 0.25, cfg.GetConfig().Jaeger.SamplerParam)
+		assert.Equal(t, "info", cfg.GetConfig().Log.Level)
+	})
+	t.Run("test config flag over env", func(t *testing.T) {
+		t.Setenv("COBAAPP_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+		path := writeConfigFile(t, `{"app" : {"name" : "from-flag", "cursor_secret" : "secret"}}`)
+
+		cfg, err := config.LoadConfig([]string{"--config=" + path})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "from-flag", cfg.GetConfig().App.Name)
+	})
+	t.Run("test unknown flag", func(t *testing.T) {
+		_, err := config.LoadConfig([]string{"--unknown"})
+		assert.Error(t, err)
+	})
+	t.Run("test command args", func(t *testing.T) {
+		t.Setenv("COBAAPP_APP_CURSOR_SECRET", "secret")
+
+		cfg, err := config.LoadConfig([]string{"migrate", "--log.level=info", "to", "2"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"migrate", "to", "2"}, cfg.Args())
+		assert.Equal(t, "info", cfg.GetConfig().Log.Level)
+	})
+}
+
+func TestDumpConfig(t *testing.T) {
+	t.Setenv("COBAAPP_APP_CURSOR_SECRET", "cursor-secret")
+	t.Setenv("COBAAPP_DATABASE_PASSWORD", "db-password")
+
+	cfg, err := config.LoadConfig(nil)
+	assert.Nil(t, err)
+
+	dump := cfg.GetConfig().Dump()
+	content, _ := json.Marshal(dump)
+
+	assert.Equal(t, "******", dump["app"]["cursor_secret"])
+	assert.Equal(t, "******", dump["database"]["password"])
+	assert.Equal(t, "30m0s", dump["database"]["conn_max_lifetime"])
+	assert.Equal(t, 100, dump["rate_limit"]["max"])
+	assert.NotContains(t, string(content), "db-password")
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("test report every problem", func(t *testing.T) {
+		path := writeConfigFile(t, `{
+			"app" : {"port" : 0},
+			"database" : {"host" : "bad host!", "port" : "abc", "max_open_conns" : 5, "max_idle_conns" : 10},
+			"jaeger" : {"sampler_type" : "probabilistic", "sampler_param" : 2},
+			"log" : {"level" : "loud"},
+			"cache" : {"ttl" : "1 minute"}
+		}`)
+
+		cfg, err := config.LoadConfig([]string{"--config", path})
+
+		assert.Nil(t, cfg)
+		validationError, ok := err.(*config.ValidationError)
+		assert.True(t, ok)
+		assert.ElementsMatch(t, []string{
+			`database.port : invalid value "abc"`,
+			`cache.ttl : invalid value "1 minute"`,
+			"app.port : must be between 1 and 65535, got 0",
+			"app.cursor_secret : is required",
+			`database.host : invalid hostname "bad host!"`,
+			"database.port : must be between 1 and 65535, got 0",
+			"database.max_idle_conns : cant be greater than max_open_conns",
+			"jaeger.sampler_param : must be between 0 and 1 for probabilistic sampler",
+			`log.level : unknown level "loud"`,
+			"cache.ttl : must be greater than 0",
+		}, validationError.Problems)
+	})
+	t.Run("test valid config", func(t *testing.T) {
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}, "database" : {"host" : "10.0.0.1"}}`)
+
+		cfg, err := config.LoadConfig([]string{"--config", path})
+
+		assert.Nil(t, err)
+		assert.Nil(t, cfg.GetConfig().Validate())
+	})
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Run("test reload notify subscriber", func(t *testing.T) {
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}, "log" : {"level" : "debug"}}`)
+		cfg, err := config.LoadConfig([]string{"--config", path})
+		assert.Nil(t, err)
+
+		var level string
+		cfg.Subscribe(func(configApp *config.ConfigApp) {
+			level = configApp.Log.Level
+		})
+
+		os.WriteFile(path, []byte(`{"app" : {"cursor_secret" : "secret"}, "log" : {"level" : "warn"}}`), 0600)
+
+		assert.Nil(t, cfg.Reload())
+		assert.Equal(t, "warn", level)
+		assert.Equal(t, "warn", cfg.GetConfig().Log.Level)
+	})
+	t.Run("test reload skip restart setting", func(t *testing.T) {
+		passwordPath := filepath.Join(t.TempDir(), "password")
+		os.WriteFile(passwordPath, []byte("old"), 0600)
+
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}, "database" : {"password_file" : "`+passwordPath+`"}}`)
+		cfg, err := config.LoadConfig([]string{"--config", path})
+		assert.Nil(t, err)
+
+		var level string
+		cfg.Subscribe(func(configApp *config.ConfigApp) {
+			level = configApp.Log.Level
+		})
+
+		// rotated secret and new port come with runtime setting in the same reload
+		os.WriteFile(passwordPath, []byte("new"), 0600)
+		os.WriteFile(path, []byte(`{"app" : {"cursor_secret" : "secret", "port" : 6000}, "database" : {"password_file" : "`+passwordPath+`"}, "log" : {"level" : "warn"}, "cache" : {"ttl" : "5m"}}`), 0600)
+
+		err = cfg.Reload()
+		assert.IsType(t, &config.RestartRequiredError{}, err)
+		assert.Equal(t, []string{"app.port", "database.password"}, err.(*config.RestartRequiredError).Keys)
+		assert.Equal(t, "warn", level)
+		assert.Equal(t, "warn", cfg.GetConfig().Log.Level)
+		assert.Equal(t, 5*time.Minute, cfg.GetConfig().Cache.TTL)
+		assert.Equal(t, 5005, cfg.GetConfig().App.Port)
+		assert.Equal(t, "old", cfg.GetConfig().Database.Password.Value())
+	})
+	t.Run("test reload keep config when invalid", func(t *testing.T) {
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}}`)
+		cfg, err := config.LoadConfig([]string{"--config", path})
+		assert.Nil(t, err)
+
+		os.WriteFile(path, []byte(`{"app" : {"cursor_secret" : "secret"}, "log" : {"level" : "loud"}}`), 0600)
+
+		assert.IsType(t, &config.ValidationError{}, cfg.Reload())
+		assert.Equal(t, "debug", cfg.GetConfig().Log.Level)
+	})
+	t.Run("test watch file change", func(t *testing.T) {
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}}`)
+		cfg, err := config.LoadConfig([]string{"--config", path})
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		reloaded := make(chan error, 1)
+		assert.Nil(t, cfg.Watch(ctx, func(err error) {
+			reloaded <- err
+		}))
+
+		os.WriteFile(path, []byte(`{"app" : {"cursor_secret" : "secret"}, "cache" : {"ttl" : "5m"}}`), 0600)
+
+		select {
+		case err := <-reloaded:
+			assert.Nil(t, err)
+			assert.Equal(t, 5*time.Minute, cfg.GetConfig().Cache.TTL)
+		case <-time.After(5 * time.Second):
+			t.Fatal("config not reloaded")
+		}
+	})
+}
+
+func TestSecretConfig(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	t.Run("test read secret from file", func(t *testing.T) {
+		secretPath := filepath.Join(t.TempDir(), "db_password")
+		os.WriteFile(secretPath, []byte("from-file\n"), 0600)
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}, "database" : {"password" : "plain", "password_file" : "`+secretPath+`"}}`)
+
+		cfg, err := config.LoadConfig([]string{"--config", path})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "from-file", cfg.GetConfig().Database.Password.Value())
+	})
+	t.Run("test secret file not found", func(t *testing.T) {
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}}`)
+		t.Setenv("COBAAPP_DATABASE_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := config.LoadConfig([]string{"--config", path})
+
+		assert.IsType(t, &config.ValidationError{}, err)
+		assert.Contains(t, err.Error(), "database.password_file : cant read secret file")
+	})
+	t.Run("test decrypt encrypted value", func(t *testing.T) {
+		encrypted, err := config.EncryptSecret(key, "s3cret")
+		assert.Nil(t, err)
+
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "secret"}, "database" : {"password" : "`+encrypted+`"}}`)
+		t.Setenv("COBAAPP_SECRET_KEY", base64.StdEncoding.EncodeToString(key))
+
+		cfg, err := config.LoadConfig([]string{"--config", path})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cret", cfg.GetConfig().Database.Password.Value())
+	})
+	t.Run("test encrypted value without key", func(t *testing.T) {
+		encrypted, _ := config.EncryptSecret(key, "s3cret")
+		path := writeConfigFile(t, `{"app" : {"cursor_secret" : "`+encrypted+`"}}`)
+
+		_, err := config.LoadConfig([]string{"--config", path})
+
+		assert.IsType(t, &config.ValidationError{}, err)
+		assert.Contains(t, err.Error(), "app.cursor_secret : is encrypted but secret.key is not set")
+	})
+	t.Run("test wrong key", func(t *testing.T) {
+		encrypted, _ := config.EncryptSecret(key, "s3cret")
+
+		_, err := config.DecryptSecret([]byte("fedcba9876543210fedcba9876543210"), encrypted)
+		assert.Error(t, err)
+	})
+	t.Run("test secret redacted", func(t *testing.T) {
+		configApp := &config.ConfigApp{
+			App:      &config.App{Name: "cobaApp", CursorSecret: "cursor-secret"},
+			Database: &config.Database{User: "root", Password: "db-password"},
+		}
+
+		dump, _ := json.Marshal(configApp)
+		printed := fmt.Sprintf("%+v %+v %#v", *configApp.App, *configApp.Database, *configApp.Database)
+
+		for _, output := range []string{string(dump), printed} {
+			assert.NotContains(t, output, "cursor-secret")
+			assert.NotContains(t, output, "db-password")
+			assert.Contains(t, output, "******")
+		}
+	})
+}
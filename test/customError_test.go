@@ -0,0 +1,109 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/customError"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomError(t *testing.T) {
+	t.Run("test default code", func(t *testing.T) {
+		err := customError.NewConflictError("car already exist")
+
+		assert.Equal(t, "car already exist", err.Error())
+		assert.Equal(t, "conflict", customError.Code(err))
+	})
+	t.Run("test wrap cause", func(t *testing.T) {
+		err := customError.NewInternalServerError("cant get car", customError.WithCause(sql.ErrConnDone))
+
+		assert.Equal(t, "cant get car", err.Error())
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Equal(t, sql.ErrConnDone, errors.Unwrap(err))
+	})
+	t.Run("test message from cause", func(t *testing.T) {
+		err := customError.NewServiceUnavailableError("", customError.WithCause(sql.ErrConnDone))
+
+		assert.Equal(t, sql.ErrConnDone.Error(), err.Error())
+	})
+	t.Run("test is kind sentinel", func(t *testing.T) {
+		err := fmt.Errorf("get car : %w", customError.NewNotFoundError("car not found", customError.WithCode("car_not_found")))
+
+		assert.ErrorIs(t, err, customError.ErrNotFound)
+		assert.NotErrorIs(t, err, customError.ErrBadRequest)
+		assert.Equal(t, "car_not_found", customError.Code(err))
+	})
+	t.Run("test is same code", func(t *testing.T) {
+		err := customError.NewForbiddenError("read only", customError.WithCode("car_read_only"))
+
+		assert.ErrorIs(t, err, customError.NewForbiddenError("", customError.WithCode("car_read_only")))
+		assert.NotErrorIs(t, err, customError.NewForbiddenError("", customError.WithCode("car_locked")))
+	})
+	t.Run("test as", func(t *testing.T) {
+		err := fmt.Errorf("login : %w", customError.NewUnauthorizedError("token expired"))
+
+		var unauthorized *customError.UnauthorizedError
+		assert.True(t, errors.As(err, &unauthorized))
+		assert.Equal(t, "token expired", unauthorized.Error())
+	})
+	t.Run("test details", func(t *testing.T) {
+		err := customError.NewBadRequestError("invalid car", customError.WithDetails(
+			customError.Detail{Field: "price", Rule: "gt", Message: "price must be greater than 0"},
+		))
+
+		assert.Len(t, customError.Details(err), 1)
+		assert.Equal(t, "price", customError.Details(err)[0].Field)
+		assert.Nil(t, customError.Details(errors.New("plain")))
+	})
+	t.Run("test status code of every kind", func(t *testing.T) {
+		kinds := map[error]int{
+			customError.ErrBadRequest:           http.StatusBadRequest,
+			customError.ErrUnauthorized:         http.StatusUnauthorized,
+			customError.ErrForbidden:            http.StatusForbidden,
+			customError.ErrNotFound:             http.StatusNotFound,
+			customError.ErrConflict:             http.StatusConflict,
+			customError.ErrPreconditionFailed:   http.StatusPreconditionFailed,
+			customError.ErrPreconditionRequired: http.StatusPreconditionRequired,
+			customError.ErrTooManyRequests:      http.StatusTooManyRequests,
+			customError.ErrInternalServer:       http.StatusInternalServerError,
+			customError.ErrServiceUnavailable:   http.StatusServiceUnavailable,
+		}
+
+		for err, expected := range kinds {
+			statusCode, ok := customError.StatusCode(err)
+
+			assert.True(t, ok)
+			assert.Equal(t, expected, statusCode)
+			assert.Equal(t, err.Error(), helper.CodeToStatus(statusCode))
+		}
+	})
+	t.Run("test error handler write code and details", func(t *testing.T) {
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			return customError.NewConflictError("car already exist", customError.WithCode("car_duplicate"),
+				customError.WithDetails(customError.Detail{Field: "name", Message: "already used"}))
+		})
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, err)
+
+		body, _ := io.ReadAll(response.Body)
+		var responseBody dto.ApiResponse
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+		assert.Equal(t, "conflict", responseBody.Status)
+		assert.Equal(t, "car_duplicate", responseBody.Code)
+		assert.Equal(t, "name", responseBody.Details[0].Field)
+	})
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/database"
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWaitDatabase(t *testing.T) {
+	dbConfig := &config.Database{
+		ConnectRetry:      2,
+		ConnectBackoff:    time.Millisecond,
+		ConnectMaxBackoff: 2 * time.Millisecond,
+		ConnectTimeout:    time.Second,
+	}
+
+	t.Run("test success after retry", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+
+		// mock
+		dbMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		dbMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		dbMock.ExpectPing()
+
+		err := database.WaitDatabase(context.Background(), db, dbConfig, logrus.New())
+
+		assert.Nil(t, err)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test retry run out", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+
+		// mock
+		for i := 0; i < 3; i++ {
+			dbMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		}
+
+		err := database.WaitDatabase(context.Background(), db, dbConfig, logrus.New())
+
+		assert.EqualError(t, err, "cant ping database after 3 attempt : connection refused")
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test context canceled", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// mock
+		dbMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		slowConfig := &config.Database{
+			ConnectRetry:      5,
+			ConnectBackoff:    time.Hour,
+			ConnectMaxBackoff: time.Hour,
+			ConnectTimeout:    time.Second,
+		}
+		err := database.WaitDatabase(ctx, db, slowConfig, logrus.New())
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,329 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/customError"
+	"cobaApp/handler"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	mck "cobaApp/test/mock"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// validate is shared with error handler so its error can be translated
+var translatedValidate = helper.NewValidator()
+
+// testTranslator translate error of translatedValidate, used by handler under test
+var testTranslator, _ = helper.NewValidationTranslator(translatedValidate)
+
+// testErrorHandler return error handler with the given http.error_format
+func testErrorHandler(format string) fiber.ErrorHandler {
+	cfg := &config.Config{ConfigApp: &config.ConfigApp{Http: &config.Http{ErrorFormat: format}}}
+	return handler.NewErrorHandler(cfg, testTranslator, logrus.New())
+}
+
+func TestErrorRegistry(t *testing.T) {
+	t.Run("test registered type", func(t *testing.T) {
+		statusCode, ok := customError.StatusCode(customError.NewNotFoundError("car not found"))
+
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, statusCode)
+	})
+	t.Run("test wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("update car : %w", customError.NewPreconditionFailedError("version changed"))
+
+		statusCode, ok := customError.StatusCode(err)
+
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, statusCode)
+	})
+	t.Run("test wrapped cause keep status of its wrapper", func(t *testing.T) {
+		for _, cause := range []error{customError.NewNotFoundError("car not found"), customError.NewBadRequestError("invalid id")} {
+			err := customError.NewInternalServerError("cant load car", customError.WithCause(cause))
+
+			statusCode, ok := customError.StatusCode(err)
+
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusInternalServerError, statusCode)
+			assert.Equal(t, "internal_server_error", customError.Code(err))
+		}
+	})
+	t.Run("test unknown error", func(t *testing.T) {
+		statusCode, ok := customError.StatusCode(errors.New("connection reset"))
+
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+	})
+}
+
+func TestErrorHandler(t *testing.T) {
+	decode := func(response *http.Response) dto.ApiResponse {
+		body, _ := io.ReadAll(response.Body)
+		var responseBody dto.ApiResponse
+		json.Unmarshal(body, &responseBody)
+		return responseBody
+	}
+
+	t.Run("test validation error is bad request", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Post("/", carHandler.InsertData)
+
+		// mock, error from validator
+		validationErr := helper.NewValidator().Struct(&dto.InsertCarRequest{Name: "Toyota", ReleaseDate: "2020-10-10"})
+		carService.Mock.On("Insert", mock.Anything, mock.Anything).Return(nil, validationErr)
+
+		// create request
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name" : "Toyota", "release_date" : "2020-10-10"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+
+		responseBody := decode(response)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, http.StatusBadRequest, responseBody.StatusCode)
+		assert.Equal(t, "error on field [price] with tag [required]", responseBody.Message)
+	})
+	t.Run("test fiber error keep its code", func(t *testing.T) {
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		assert.Nil(t, err)
+
+		responseBody := decode(response)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		assert.Equal(t, "not found", responseBody.Status)
+	})
+	t.Run("test failed bulk keep the report", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Post("/", carHandler.InsertBulk)
+
+		// mock
+		carService.Mock.On("InsertBulk", mock.Anything, mock.Anything, "").
+			Return(&dto.BulkInsertCarResponse{Mode: "atomic", Total: 1, Failed: 1}, customError.NewBadRequestError("1 of 1 data is invalid"))
+
+		// create request
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[{"name" : "Toyota"}]`))
+		request.Header.Add("Content-Type", "application/json")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+
+		responseBody := decode(response)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, "1 of 1 data is invalid", responseBody.Message)
+		assert.Equal(t, float64(1), responseBody.Data.(map[string]any)["failed"])
+	})
+}
+
+func TestProblemErrorHandler(t *testing.T) {
+	decode := func(response *http.Response) dto.ProblemResponse {
+		body, _ := io.ReadAll(response.Body)
+		var problem dto.ProblemResponse
+		json.Unmarshal(body, &problem)
+		return problem
+	}
+	newApp := func(format string) *fiber.App {
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(format)})
+		app.Get("/car/:id", func(ctx *fiber.Ctx) error {
+			return customError.NewNotFoundError("car not found")
+		})
+		return app
+	}
+
+	t.Run("test problem from config", func(t *testing.T) {
+		response, err := newApp(config.ErrorFormatProblem).Test(httptest.NewRequest(http.MethodGet, "/car/9?lang=id", nil))
+		assert.Nil(t, err)
+
+		problem := decode(response)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+		assert.Equal(t, "urn:cobaApp:problem:not_found", problem.Type)
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "car not found", problem.Detail)
+		assert.Equal(t, "/car/9?lang=id", problem.Instance)
+	})
+	t.Run("test problem from accept", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/car/9", nil)
+		request.Header.Set("Accept", "application/problem+json, application/json;q=0.5")
+
+		response, err := newApp(config.ErrorFormatApiResponse).Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+	})
+	t.Run("test api response stay default", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/car/9", nil)
+		request.Header.Set("Accept", "*/*")
+
+		response, err := newApp(config.ErrorFormatApiResponse).Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	})
+	t.Run("test validation errors extension", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		carHandler := handler.NewCarHandler(carService, testTranslator, logrus.New())
+
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatProblem)})
+		app.Post("/", carHandler.InsertData)
+
+		// mock, error from validator
+		validationErr := helper.NewValidator().Struct(&dto.InsertCarRequest{Name: "Toyota", Price: -1})
+		carService.Mock.On("Insert", mock.Anything, mock.Anything).Return(nil, validationErr)
+
+		// create request
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name" : "Toyota", "price" : -1}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+
+		problem := decode(response)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Len(t, problem.Errors, 2)
+		assert.Equal(t, "price", problem.Errors[0].Field)
+		assert.Equal(t, "gt", problem.Errors[0].Rule)
+	})
+}
+
+func TestTranslatedValidationError(t *testing.T) {
+	newApp := func() *fiber.App {
+		app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+		app.Post("/", func(ctx *fiber.Ctx) error {
+			return translatedValidate.Struct(&dto.InsertCarRequest{Name: "Toyota", Price: -1, ReleaseDate: "2020-10-10"})
+		})
+		return app
+	}
+	send := func(acceptLanguage string) dto.ApiResponse {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		if acceptLanguage != "" {
+			request.Header.Set("Accept-Language", acceptLanguage)
+		}
+
+		response, err := newApp().Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		var responseBody dto.ApiResponse
+		json.Unmarshal(body, &responseBody)
+		return responseBody
+	}
+
+	t.Run("test english by default", func(t *testing.T) {
+		responseBody := send("")
+
+		assert.Equal(t, "price must be greater than 0.000", responseBody.Message)
+		assert.Equal(t, []customError.Detail{
+			{Field: "price", Rule: "gt", Param: "0.000", Message: "price must be greater than 0.000"},
+		}, responseBody.Details)
+	})
+	t.Run("test indonesian from accept language", func(t *testing.T) {
+		responseBody := send("id-ID,id;q=0.9,en;q=0.8")
+
+		assert.Equal(t, "price", responseBody.Details[0].Field)
+		assert.Equal(t, "price harus lebih besar dari 0,000", responseBody.Details[0].Message)
+	})
+	t.Run("test quality order", func(t *testing.T) {
+		responseBody := send("id;q=0.3, en;q=0.7")
+
+		assert.Equal(t, "price must be greater than 0.000", responseBody.Message)
+	})
+	t.Run("test unsupported language fallback english", func(t *testing.T) {
+		responseBody := send("fr-FR")
+
+		assert.Equal(t, "price must be greater than 0.000", responseBody.Message)
+	})
+}
+
+func TestStrictJSONBody(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse),
+		JSONDecoder:  helper.StrictJSONUnmarshal,
+	})
+	app.Post("/", func(ctx *fiber.Ctx) error {
+		var request dto.InsertCarRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return customError.NewBadRequestError(err.Error())
+		}
+		return ctx.SendStatus(http.StatusOK)
+	})
+	send := func(body string) (int, dto.ApiResponse) {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+
+		responseBody := dto.ApiResponse{}
+		json.NewDecoder(response.Body).Decode(&responseBody)
+		return response.StatusCode, responseBody
+	}
+
+	t.Run("test known field accepted", func(t *testing.T) {
+		status, _ := send(`{"name":"Toyota","price":1,"release_date":"2020-10-10"}`)
+
+		assert.Equal(t, http.StatusOK, status)
+	})
+	t.Run("test unknown field rejected", func(t *testing.T) {
+		status, responseBody := send(`{"name":"Toyota","price":1,"release_date":"2020-10-10","colour":"red"}`)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, responseBody.Message, `unknown field "colour"`)
+	})
+	t.Run("test trailing data rejected", func(t *testing.T) {
+		status, _ := send(`{"name":"Toyota"} {"name":"Honda"}`)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestTranslatedDateValidationError(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+	app.Post("/:date", func(ctx *fiber.Ctx) error {
+		return translatedValidate.Struct(&dto.InsertCarRequest{Name: "Toyota", Price: 1, ReleaseDate: ctx.Params("date")})
+	})
+	send := func(date string, acceptLanguage string) dto.ApiResponse {
+		request := httptest.NewRequest(http.MethodPost, "/"+date, nil)
+		request.Header.Set("Accept-Language", acceptLanguage)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		responseBody := dto.ApiResponse{}
+		json.NewDecoder(response.Body).Decode(&responseBody)
+		return responseBody
+	}
+
+	t.Run("test invalid calendar date", func(t *testing.T) {
+		responseBody := send("2020-02-30", "en")
+
+		assert.Equal(t, []customError.Detail{
+			{Field: "release_date", Rule: "date", Message: "release_date must be a valid date with format YYYY-MM-DD"},
+		}, responseBody.Details)
+	})
+	t.Run("test min year in indonesian", func(t *testing.T) {
+		responseBody := send("1885-01-01", "id")
+
+		assert.Equal(t, "release_date tidak boleh sebelum tahun 1886", responseBody.Message)
+		assert.Equal(t, "1886", responseBody.Details[0].Param)
+	})
+}
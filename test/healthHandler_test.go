@@ -0,0 +1,146 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/handler"
+	"cobaApp/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type tracerCheckStub struct {
+	err error
+}
+
+func (t *tracerCheckStub) Check(ctx context.Context) error {
+	return t.err
+}
+
+type transportStub struct {
+	jaeger.Transport
+	err error
+}
+
+func (t *transportStub) Flush() (int, error) {
+	return 0, t.err
+}
+
+func (t *transportStub) Close() error {
+	return nil
+}
+
+func readinessBody(t *testing.T, response *http.Response) map[string]any {
+	body, _ := io.ReadAll(response.Body)
+	responseBody := map[string]any{}
+	if err := json.Unmarshal(body, &responseBody); err != nil {
+		t.Fatal(err)
+	}
+	return responseBody["data"].(map[string]any)
+}
+
+func TestHealthHandler(t *testing.T) {
+	cfg := &config.Config{ConfigApp: &config.ConfigApp{Health: &config.Health{Timeout: time.Second}}}
+
+	t.Run("test liveness", func(t *testing.T) {
+		healthHandler := handler.NewHealthHandler(nil, nil, cfg)
+
+		app := fiber.New()
+		app.Get("/healthz", healthHandler.Liveness)
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+	t.Run("test readiness tracer down still ready", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+
+		healthHandler := handler.NewHealthHandler(db, &tracerCheckStub{err: errors.New("connection refused")}, cfg)
+
+		app := fiber.New()
+		app.Get("/readyz", healthHandler.Readiness)
+
+		// mock
+		dbMock.ExpectPing()
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		data := readinessBody(t, response)
+		checks := data["checks"].(map[string]any)
+		assert.Equal(t, "up", data["status"])
+		assert.Equal(t, "up", checks["database"].(map[string]any)["status"])
+		assert.Equal(t, "down", checks["tracer"].(map[string]any)["status"])
+		assert.Equal(t, "connection refused", checks["tracer"].(map[string]any)["error"])
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test readiness database down", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+
+		healthHandler := handler.NewHealthHandler(db, nil, cfg)
+
+		app := fiber.New()
+		app.Get("/readyz", healthHandler.Readiness)
+
+		// mock
+		dbMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+		data := readinessBody(t, response)
+		assert.Equal(t, "down", data["status"])
+		assert.Equal(t, "down", data["checks"].(map[string]any)["database"].(map[string]any)["status"])
+	})
+	t.Run("test readiness shutting down", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+
+		healthHandler := handler.NewHealthHandler(db, nil, cfg)
+		healthHandler.MarkShuttingDown()
+
+		app := fiber.New()
+		app.Get("/readyz", healthHandler.Readiness)
+
+		// mock
+		dbMock.ExpectPing()
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+		data := readinessBody(t, response)
+		assert.Equal(t, "shutting down", data["checks"].(map[string]any)["server"].(map[string]any)["error"])
+	})
+}
+
+func TestReporterHealth(t *testing.T) {
+	transport := &transportStub{}
+	reporterHealth := &tracing.ReporterHealth{Transport: transport}
+
+	assert.Nil(t, reporterHealth.Check(context.Background()))
+
+	transport.err = errors.New("connection refused")
+	reporterHealth.Flush()
+	assert.EqualError(t, reporterHealth.Check(context.Background()), "connection refused")
+
+	transport.err = nil
+	reporterHealth.Flush()
+	assert.Nil(t, reporterHealth.Check(context.Background()))
+
+	reporterHealth.Close()
+	assert.ErrorIs(t, reporterHealth.Check(context.Background()), tracing.ErrReporterClosed)
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"cobaApp/cache"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test evict least recently used", func(t *testing.T) {
+		lru := cache.NewLRUCache(2)
+
+		lru.Set(ctx, "a", []byte("1"), 0)
+		lru.Set(ctx, "b", []byte("2"), 0)
+
+		// a become most recently used, so b is evicted
+		_, err := lru.Get(ctx, "a")
+		assert.Nil(t, err)
+		lru.Set(ctx, "c", []byte("3"), 0)
+
+		_, err = lru.Get(ctx, "b")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+		value, err := lru.Get(ctx, "a")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("1"), value)
+	})
+	t.Run("test expired by ttl", func(t *testing.T) {
+		lru := cache.NewLRUCache(10)
+
+		lru.Set(ctx, "a", []byte("1"), 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := lru.Get(ctx, "a")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+	t.Run("test delete prefix", func(t *testing.T) {
+		lru := cache.NewLRUCache(10)
+
+		lru.Set(ctx, "cars:list:1", []byte("1"), 0)
+		lru.Set(ctx, "cars:count:1", []byte("1"), 0)
+		lru.Set(ctx, "car:1", []byte("1"), 0)
+
+		lru.DeletePrefix(ctx, "cars:")
+
+		_, err := lru.Get(ctx, "cars:list:1")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+		_, err = lru.Get(ctx, "cars:count:1")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+		_, err = lru.Get(ctx, "car:1")
+		assert.Nil(t, err)
+	})
+}
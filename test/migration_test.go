@@ -0,0 +1,139 @@
+package test
+
+import (
+	"cobaApp/migration"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var migrationFiles = fstest.MapFS{
+	"0002_add_color.up.sql":     {Data: []byte("-- add color\nALTER TABLE cars ADD color varchar(20);\n")},
+	"0002_add_color.down.sql":   {Data: []byte("ALTER TABLE cars DROP color;\n")},
+	"0001_create_cars.up.sql":   {Data: []byte("CREATE TABLE cars (\n  id int\n);\nINSERT INTO cars(id) VALUES (1);\n")},
+	"0001_create_cars.down.sql": {Data: []byte("DROP TABLE cars;\n")},
+}
+
+func TestLoadMigration(t *testing.T) {
+	t.Run("test success sorted by version", func(t *testing.T) {
+		migrations, err := migration.Load(migrationFiles)
+
+		assert.Nil(t, err)
+		assert.Len(t, migrations, 2)
+		assert.Equal(t, 1, migrations[0].Version)
+		assert.Equal(t, "create_cars", migrations[0].Name)
+		assert.Equal(t, 2, migrations[1].Version)
+	})
+	t.Run("test missing down file", func(t *testing.T) {
+		_, err := migration.Load(fstest.MapFS{
+			"0001_create_cars.up.sql": {Data: []byte("CREATE TABLE cars (id int);")},
+		})
+
+		assert.EqualError(t, err, "migration 0001_create_cars need both up and down file")
+	})
+	t.Run("test invalid file name", func(t *testing.T) {
+		_, err := migration.Load(fstest.MapFS{
+			"create_cars.sql": {Data: []byte("CREATE TABLE cars (id int);")},
+		})
+
+		assert.EqualError(t, err, "invalid migration file name create_cars.sql")
+	})
+	t.Run("test embedded migration", func(t *testing.T) {
+		migrations, err := migration.Embedded()
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, migrations)
+		assert.Equal(t, 1, migrations[0].Version)
+
+		// baseline must not fail on database created before migration
+		assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS `cars`")
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	migrations, _ := migration.Load(migrationFiles)
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("test up apply pending only", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		// mock
+		dbMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+		dbMock.ExpectExec("ALTER TABLE cars ADD color").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec("INSERT INTO schema_migrations").WithArgs(2, "add_color").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		done, err := migration.NewMigrator(db, migrations, logrus.New()).Up(context.Background())
+
+		assert.Nil(t, err)
+		assert.Len(t, done, 1)
+		assert.Equal(t, 2, done[0].Version)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test up run every statement", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		// mock
+		dbMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+		dbMock.ExpectExec("CREATE TABLE cars").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec("INSERT INTO cars").WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectExec("INSERT INTO schema_migrations").WithArgs(1, "create_cars").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		done, err := migration.NewMigrator(db, migrations, logrus.New()).To(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Len(t, done, 1)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test down roll back last applied", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		// mock
+		dbMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt).AddRow(2, appliedAt))
+		dbMock.ExpectExec("ALTER TABLE cars DROP color").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec("DELETE FROM schema_migrations").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		done, err := migration.NewMigrator(db, migrations, logrus.New()).Down(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, done.Version)
+		assert.Nil(t, dbMock.ExpectationsWereMet())
+	})
+	t.Run("test to unknown version", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		_, err := migration.NewMigrator(db, migrations, logrus.New()).To(context.Background(), 9)
+
+		assert.EqualError(t, err, "migration version 9 not found")
+	})
+	t.Run("test status", func(t *testing.T) {
+		db, dbMock, _ := sqlmock.New()
+		defer db.Close()
+
+		// mock
+		dbMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+
+		statuses, err := migration.NewMigrator(db, migrations, logrus.New()).Status(context.Background())
+
+		assert.Nil(t, err)
+		assert.Len(t, statuses, 2)
+		assert.True(t, statuses[0].Applied)
+		assert.Equal(t, appliedAt, *statuses[0].AppliedAt)
+		assert.False(t, statuses[1].Applied)
+	})
+}
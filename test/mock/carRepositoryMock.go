@@ -1,10 +1,12 @@
 package mock
 
 import (
+	"cobaApp/model/dto"
 	"cobaApp/model/entity"
 	"context"
 	"database/sql"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type CarRepositoryMock struct {
@@ -27,8 +29,8 @@ func (c *CarRepositoryMock) Insert(ctx context.Context, tx *sql.Tx, input *entit
 	return value.(*entity.Car), nil
 }
 
-func (c *CarRepositoryMock) GetAll(ctx context.Context, tx *sql.Tx) ([]entity.Car, error) {
-	args := c.Mock.Called(ctx, tx)
+func (c *CarRepositoryMock) GetAll(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) ([]entity.Car, error) {
+	args := c.Mock.Called(ctx, query)
 
 	value := args.Get(0)
 	if value == nil {
@@ -38,6 +40,18 @@ func (c *CarRepositoryMock) GetAll(ctx context.Context, tx *sql.Tx) ([]entity.Ca
 	return value.([]entity.Car), nil
 }
 
+func (c *CarRepositoryMock) Count(ctx context.Context, tx *sql.Tx, query *dto.CarQuery) (int, error) {
+	args := c.Mock.Called(ctx, query)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (c *CarRepositoryMock) LastModified(ctx context.Context, tx *sql.Tx) (time.Time, error) {
+	args := c.Mock.Called(ctx)
+
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
 func (c *CarRepositoryMock) GetDetail(ctx context.Context, tx *sql.Tx, id int) (*entity.Car, error) {
 	args := c.Mock.Called(ctx, id)
 
@@ -48,3 +62,63 @@ func (c *CarRepositoryMock) GetDetail(ctx context.Context, tx *sql.Tx, id int) (
 
 	return value.(*entity.Car), nil
 }
+
+func (c *CarRepositoryMock) Update(ctx context.Context, tx *sql.Tx, input *entity.Car) (*entity.Car, error) {
+	args := c.Mock.Called(ctx, input)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, args.Error(1)
+	}
+
+	return value.(*entity.Car), nil
+}
+
+func (c *CarRepositoryMock) Patch(ctx context.Context, tx *sql.Tx, id int, version int, input *entity.CarPatch) error {
+	args := c.Mock.Called(ctx, id, version, input)
+
+	return args.Error(0)
+}
+
+func (c *CarRepositoryMock) Delete(ctx context.Context, tx *sql.Tx, id int, version int) error {
+	args := c.Mock.Called(ctx, id, version)
+
+	return args.Error(0)
+}
+
+func (c *CarRepositoryMock) Iterate(ctx context.Context, tx *sql.Tx, fn func(car *entity.Car) error) error {
+	args := c.Mock.Called(ctx)
+
+	// feed the given cars to callback
+	if len(args) > 1 {
+		cars := args.Get(1).([]entity.Car)
+		for i := range cars {
+			if err := fn(&cars[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return args.Error(0)
+}
+
+func (c *CarRepositoryMock) IsDuplicate(ctx context.Context, tx *sql.Tx, name string, releaseDate time.Time, excludeId int) (bool, error) {
+	args := c.Mock.Called(ctx, name, releaseDate, excludeId)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (c *CarRepositoryMock) Restore(ctx context.Context, tx *sql.Tx, id int) error {
+	args := c.Mock.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (c *CarRepositoryMock) Purge(ctx context.Context, tx *sql.Tx, before time.Time) (int, error) {
+	args := c.Mock.Called(ctx, before)
+
+	return args.Int(0), args.Error(1)
+}
+
+// Invalidate has no effect on the result of service, so it is not recorded
+func (c *CarRepositoryMock) Invalidate(ctx context.Context, ids ...int) {}
@@ -4,6 +4,8 @@ import (
 	"cobaApp/model/dto"
 	"context"
 	"github.com/stretchr/testify/mock"
+	"io"
+	"time"
 )
 
 type CarServiceMock struct {
@@ -26,18 +28,107 @@ func (c *CarServiceMock) Insert(ctx context.Context, request *dto.InsertCarReque
 	return value.(*dto.InsertCarResponse), nil
 }
 
-func (c *CarServiceMock) GetAll(ctx context.Context) ([]dto.InsertCarResponse, error) {
+func (c *CarServiceMock) GetAll(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error) {
+	args := c.Mock.Called(ctx, query)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, nil, args.Error(2)
+	}
+
+	return value.([]dto.InsertCarResponse), args.Get(1).(*dto.PageMeta), nil
+}
+
+func (c *CarServiceMock) GetDetail(ctx context.Context, id int) (*dto.InsertCarResponse, error) {
+	args := c.Mock.Called(ctx, id)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, args.Error(1)
+	}
+
+	return value.(*dto.InsertCarResponse), nil
+}
+
+func (c *CarServiceMock) LastModified(ctx context.Context) (time.Time, error) {
 	args := c.Mock.Called(ctx)
 
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (c *CarServiceMock) Update(ctx context.Context, id int, version int, request *dto.UpdateCarRequest) (*dto.InsertCarResponse, error) {
+	args := c.Mock.Called(ctx, id, version, request)
+
 	value := args.Get(0)
 	if value == nil {
 		return nil, args.Error(1)
 	}
 
-	return value.([]dto.InsertCarResponse), nil
+	return value.(*dto.InsertCarResponse), nil
 }
 
-func (c *CarServiceMock) GetDetail(ctx context.Context, id int) (*dto.InsertCarResponse, error) {
+func (c *CarServiceMock) Patch(ctx context.Context, id int, version int, request *dto.PatchCarRequest) (*dto.InsertCarResponse, error) {
+	args := c.Mock.Called(ctx, id, version, request)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, args.Error(1)
+	}
+
+	return value.(*dto.InsertCarResponse), nil
+}
+
+func (c *CarServiceMock) Delete(ctx context.Context, id int, version int) error {
+	args := c.Mock.Called(ctx, id, version)
+
+	return args.Error(0)
+}
+
+func (c *CarServiceMock) InsertBulk(ctx context.Context, requests []dto.InsertCarRequest, mode string) (*dto.BulkInsertCarResponse, error) {
+	args := c.Mock.Called(ctx, requests, mode)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, args.Error(1)
+	}
+
+	return value.(*dto.BulkInsertCarResponse), args.Error(1)
+}
+
+func (c *CarServiceMock) Import(ctx context.Context, r io.Reader, format string, mode string) (*dto.BulkInsertCarResponse, error) {
+	args := c.Mock.Called(ctx, r, format, mode)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, args.Error(1)
+	}
+
+	return value.(*dto.BulkInsertCarResponse), args.Error(1)
+}
+
+func (c *CarServiceMock) Export(ctx context.Context, w io.Writer, format string) error {
+	args := c.Mock.Called(ctx, w, format)
+
+	// write the given content, to check streaming in handler
+	if len(args) > 1 {
+		io.WriteString(w, args.String(1))
+	}
+
+	return args.Error(0)
+}
+
+func (c *CarServiceMock) GetAllDeleted(ctx context.Context, query *dto.CarQuery) ([]dto.InsertCarResponse, *dto.PageMeta, error) {
+	args := c.Mock.Called(ctx, query)
+
+	value := args.Get(0)
+	if value == nil {
+		return nil, nil, args.Error(2)
+	}
+
+	return value.([]dto.InsertCarResponse), args.Get(1).(*dto.PageMeta), nil
+}
+
+func (c *CarServiceMock) Restore(ctx context.Context, id int) (*dto.InsertCarResponse, error) {
 	args := c.Mock.Called(ctx, id)
 
 	value := args.Get(0)
@@ -47,3 +138,9 @@ func (c *CarServiceMock) GetDetail(ctx context.Context, id int) (*dto.InsertCarR
 
 	return value.(*dto.InsertCarResponse), nil
 }
+
+func (c *CarServiceMock) Purge(ctx context.Context) (int, error) {
+	args := c.Mock.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
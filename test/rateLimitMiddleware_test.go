@@ -0,0 +1,53 @@
+package test
+
+import (
+	"cobaApp/config"
+	"cobaApp/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	configApp := &config.ConfigApp{RateLimit: &config.RateLimit{Enabled: true, Max: 2, Window: time.Minute}}
+	cfg := &config.Config{ConfigApp: configApp}
+
+	app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler(config.ErrorFormatApiResponse)})
+	app.Use(middleware.RateLimitMiddleware(cfg))
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+
+	t.Run("test limit reached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+		assert.Equal(t, "0", response.Header.Get("X-RateLimit-Remaining"))
+		assert.NotEmpty(t, response.Header.Get("Retry-After"))
+	})
+	t.Run("test new limit apply live", func(t *testing.T) {
+		configApp.RateLimit = &config.RateLimit{Enabled: true, Max: 10, Window: time.Minute}
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "10", response.Header.Get("X-RateLimit-Limit"))
+	})
+	t.Run("test disabled", func(t *testing.T) {
+		configApp.RateLimit = &config.RateLimit{Enabled: false}
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Empty(t, response.Header.Get("X-RateLimit-Limit"))
+	})
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"cobaApp/customError"
+	"cobaApp/helper"
+	"cobaApp/model/dto"
+	"cobaApp/seed"
+	mck "cobaApp/test/mock"
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeedLoad(t *testing.T) {
+	t.Run("test load yaml fixture", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		seeder := seed.NewSeeder(carService, seed.NewGenerator(1), logrus.New())
+
+		// mock
+		request := &dto.InsertCarRequest{Name: "Honda Brio", Price: 193000000, ReleaseDate: "2023-03-07"}
+		carService.Mock.On("Insert", mock.Anything, request).Return(&dto.InsertCarResponse{Id: 1, Name: "Honda Brio"}, nil)
+
+		result, err := seeder.Load(context.Background(), strings.NewReader(`
+cars:
+  - name: Honda Brio
+    price: 193000000
+    release_date: "2023-03-07"
+`), seed.FormatYAML)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.Success)
+		assert.Equal(t, 1, result.Cars[0].Id)
+		carService.Mock.AssertExpectations(t)
+	})
+	t.Run("test load json fixture with failed car", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		seeder := seed.NewSeeder(carService, seed.NewGenerator(1), logrus.New())
+
+		// mock
+		carService.Mock.On("Insert", mock.Anything, &dto.InsertCarRequest{Name: "Honda Brio", Price: 193000000, ReleaseDate: "2023-03-07"}).
+			Return(&dto.InsertCarResponse{Id: 1}, nil)
+		carService.Mock.On("Insert", mock.Anything, &dto.InsertCarRequest{Name: "Free Car"}).
+			Return(nil, customError.NewBadRequestError("price is required"))
+
+		result, err := seeder.Load(context.Background(), strings.NewReader(`{"cars" : [
+			{"name" : "Honda Brio", "price" : 193000000, "release_date" : "2023-03-07"},
+			{"name" : "Free Car"}
+		]}`), seed.FormatJSON)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, result.Total)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, []string{"car 1 [Free Car] : price is required"}, result.Errors)
+	})
+	t.Run("test invalid fixture", func(t *testing.T) {
+		seeder := seed.NewSeeder(mck.NewCarServiceMock(), seed.NewGenerator(1), logrus.New())
+
+		_, err := seeder.Load(context.Background(), strings.NewReader(`cars: [`), seed.FormatYAML)
+
+		assert.Error(t, err)
+	})
+	t.Run("test load default fixture", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		seeder := seed.NewSeeder(carService, seed.NewGenerator(1), logrus.New())
+
+		// mock
+		carService.Mock.On("Insert", mock.Anything, mock.Anything).Return(&dto.InsertCarResponse{}, nil)
+
+		result, err := seeder.LoadDefault(context.Background())
+
+		assert.Nil(t, err)
+		assert.NotZero(t, result.Total)
+		assert.Equal(t, result.Total, result.Success)
+	})
+}
+
+func TestSeedGenerate(t *testing.T) {
+	t.Run("test generate valid cars", func(t *testing.T) {
+		carService := mck.NewCarServiceMock()
+		seeder := seed.NewSeeder(carService, seed.NewGenerator(42), logrus.New())
+		validate := helper.NewValidator()
+
+		// mock
+		carService.Mock.On("Insert", mock.Anything, mock.Anything).Return(&dto.InsertCarResponse{}, nil).
+			Run(func(args mock.Arguments) {
+				request := args.Get(1).(*dto.InsertCarRequest)
+				releaseDate := helper.StringToDate(request.ReleaseDate)
+
+				assert.Nil(t, validate.Struct(request))
+				assert.True(t, releaseDate.After(time.Now().AddDate(-11, 0, 0)))
+			})
+
+		result, err := seeder.Generate(context.Background(), 25)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 25, result.Success)
+		carService.Mock.AssertNumberOfCalls(t, "Insert", 25)
+	})
+	t.Run("test same seed same cars", func(t *testing.T) {
+		first, second := seed.NewGenerator(7), seed.NewGenerator(7)
+
+		assert.Equal(t, first.Car().Name, second.Car().Name)
+	})
+	t.Run("test large batch no duplicate name and release date", func(t *testing.T) {
+		generator := seed.NewGenerator(1)
+
+		used := map[string]bool{}
+		for i := 0; i < 10000; i++ {
+			car := generator.Car()
+			key := car.Name + "|" + car.ReleaseDate
+
+			assert.False(t, used[key], "duplicate car %v", key)
+			used[key] = true
+		}
+	})
+	t.Run("test invalid count", func(t *testing.T) {
+		seeder := seed.NewSeeder(mck.NewCarServiceMock(), seed.NewGenerator(1), logrus.New())
+
+		_, err := seeder.Generate(context.Background(), 0)
+
+		assert.EqualError(t, err, "count must be greater than 0, got 0")
+	})
+}
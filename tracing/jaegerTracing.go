@@ -10,24 +10,62 @@ import (
 	"io"
 )
 
-func GenerateTracing(config config.IConfig, log *logrus.Logger, serviceName string) (opentracing.Tracer, io.Closer) {
+func GenerateTracing(cfg config.IConfig, log *logrus.Logger, serviceName string) (opentracing.Tracer, io.Closer, *ReporterHealth) {
 	jaegerCfg := jaegerConfig.Configuration{
 		ServiceName: serviceName,
-		Sampler: &jaegerConfig.SamplerConfig{
-			Type:  jaeger.SamplerTypeConst,
-			Param: 1,
-		},
-		Reporter: &jaegerConfig.ReporterConfig{
-			LogSpans: false,
-			LocalAgentHostPort: fmt.Sprintf("%v:%v",
-				config.GetConfig().Jaeger.Host, config.GetConfig().Jaeger.Port),
-		},
 	}
 
-	tracer, closer, err := jaegerCfg.NewTracer(jaegerConfig.Logger(jaeger.StdLogger))
+	// transport is wrapped so readiness can report the reporter state
+	sender, err := jaeger.NewUDPTransport(fmt.Sprintf("%v:%v",
+		cfg.GetConfig().Jaeger.Host, cfg.GetConfig().Jaeger.Port), 0)
 	if err != nil {
 		log.Fatalf("cant connect jaeger : %v", err)
 	}
+	reporterHealth := &ReporterHealth{Transport: sender}
 
-	return tracer, closer
+	reporter := jaeger.NewRemoteReporter(reporterHealth, jaeger.ReporterOptions.Logger(jaeger.StdLogger))
+	if cfg.GetConfig().Jaeger.LogSpans {
+		reporter = jaeger.NewCompositeReporter(jaeger.NewLoggingReporter(jaeger.StdLogger), reporter)
+	}
+
+	// sampler is wrapped so the sampling can change on config reload
+	samplerType, samplerParam := cfg.GetConfig().Jaeger.SamplerType, cfg.GetConfig().Jaeger.SamplerParam
+	sampler, err := newSampler(serviceName, samplerType, samplerParam)
+	if err != nil {
+		log.Fatalf("cant create jaeger sampler : %v", err)
+	}
+	reloadable := &reloadableSampler{sampler: sampler}
+
+	tracer, closer, err := jaegerCfg.NewTracer(jaegerConfig.Logger(jaeger.StdLogger),
+		jaegerConfig.Sampler(reloadable), jaegerConfig.Reporter(reporter))
+	if err != nil {
+		log.Fatalf("cant connect jaeger : %v", err)
+	}
+
+	cfg.Subscribe(func(configApp *config.ConfigApp) {
+		if configApp.Jaeger.SamplerType == samplerType && configApp.Jaeger.SamplerParam == samplerParam {
+			return
+		}
+
+		sampler, err := newSampler(serviceName, configApp.Jaeger.SamplerType, configApp.Jaeger.SamplerParam)
+		if err != nil {
+			log.Errorf("cant change jaeger sampler : %v", err)
+			return
+		}
+
+		reloadable.swap(sampler)
+		samplerType, samplerParam = configApp.Jaeger.SamplerType, configApp.Jaeger.SamplerParam
+		log.Infof("jaeger sampler changed to [%v] with param [%v]", samplerType, samplerParam)
+	})
+
+	return tracer, closer, reporterHealth
+}
+
+func newSampler(serviceName string, samplerType string, samplerParam float64) (jaeger.Sampler, error) {
+	samplerCfg := &jaegerConfig.SamplerConfig{
+		Type:  samplerType,
+		Param: samplerParam,
+	}
+
+	return samplerCfg.NewSampler(serviceName, jaeger.NewNullMetrics())
 }
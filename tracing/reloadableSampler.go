@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"github.com/uber/jaeger-client-go"
+	"sync"
+)
+
+// reloadableSampler delegate to a sampler that can be replaced while the tracer is running
+type reloadableSampler struct {
+	mutex   sync.RWMutex
+	sampler jaeger.Sampler
+}
+
+func (r *reloadableSampler) IsSampled(id jaeger.TraceID, operation string) (bool, []jaeger.Tag) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.sampler.IsSampled(id, operation)
+}
+
+func (r *reloadableSampler) Close() {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.sampler.Close()
+}
+
+func (r *reloadableSampler) Equal(other jaeger.Sampler) bool {
+	return r == other
+}
+
+// swap replace the current sampler and close the old one
+func (r *reloadableSampler) swap(sampler jaeger.Sampler) {
+	r.mutex.Lock()
+	old := r.sampler
+	r.sampler = sampler
+	r.mutex.Unlock()
+
+	old.Close()
+}
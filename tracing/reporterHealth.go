@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/uber/jaeger-client-go"
+	"sync"
+)
+
+var ErrReporterClosed = errors.New("reporter closed")
+
+// ReporterHealth wrap jaeger transport to remember the result of the last flush to the agent
+type ReporterHealth struct {
+	jaeger.Transport
+
+	mutex   sync.RWMutex
+	lastErr error
+	closed  bool
+}
+
+func (r *ReporterHealth) Append(span *jaeger.Span) (int, error) {
+	flushed, err := r.Transport.Append(span)
+
+	// append only send to agent when the buffer is full
+	if flushed > 0 || err != nil {
+		r.record(err)
+	}
+	return flushed, err
+}
+
+func (r *ReporterHealth) Flush() (int, error) {
+	flushed, err := r.Transport.Flush()
+	r.record(err)
+	return flushed, err
+}
+
+func (r *ReporterHealth) Close() error {
+	r.mutex.Lock()
+	r.closed = true
+	r.mutex.Unlock()
+
+	return r.Transport.Close()
+}
+
+// Check return error of the last flush, nil when it success or nothing sent yet
+func (r *ReporterHealth) Check(ctx context.Context) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return ErrReporterClosed
+	}
+	return r.lastErr
+}
+
+func (r *ReporterHealth) record(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lastErr = err
+}